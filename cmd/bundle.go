@@ -0,0 +1,196 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+
+	"minos/config"
+	"minos/database"
+	"minos/internal/dto"
+	"minos/internal/repository"
+	"minos/internal/service"
+)
+
+const bundleUsage = `Usage:
+  minos bundle export [-o file] [-format yaml|json] [-include prompts,problems] [-prompt-name name]
+  minos bundle import [-policy skip|overwrite|new-version] [-dry-run] [-format yaml|json] [-actor name] [file]
+
+Import reads the bundle from stdin when no file is given.`
+
+// runBundleCommand runs the bundle subcommand against the configured
+// database and returns the process exit code.
+func runBundleCommand(args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, bundleUsage)
+		return 2
+	}
+
+	var err error
+	switch args[0] {
+	case "export":
+		err = exportBundleCommand(args[1:])
+	case "import":
+		err = importBundleCommand(args[1:])
+	default:
+		fmt.Fprintln(os.Stderr, bundleUsage)
+		return 2
+	}
+	if errors.Is(err, flag.ErrHelp) {
+		return 2
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bundle:", err)
+		return 1
+	}
+	return 0
+}
+
+func newBundleService() (service.BundleService, error) {
+	cfg, err := config.NewConfig()
+	if err != nil {
+		return nil, err
+	}
+	db, err := database.NewDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return service.NewBundleService(
+		repository.NewPromptTemplateRepository(db),
+		repository.NewProblemRepository(db),
+		repository.NewBundleRepository(db),
+	), nil
+}
+
+func exportBundleCommand(args []string) error {
+	flags := flag.NewFlagSet("bundle export", flag.ContinueOnError)
+	output := flags.String("o", "", "write the bundle to this file instead of stdout")
+	format := flags.String("format", "", "yaml or json, defaults to the extension of -o, then yaml")
+	include := flags.String("include", "", "comma-separated sections to export: prompts, problems (default both)")
+	promptName := flags.String("prompt-name", "", "only export the templates and channels of this prompt name")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = bundleFormatFromPath(*output)
+	}
+	if *format == "" {
+		*format = service.BundleFormatYAML
+	}
+	if *format != service.BundleFormatYAML && *format != service.BundleFormatJSON {
+		return fmt.Errorf("unknown format '%s', expected yaml or json", *format)
+	}
+
+	bundles, err := newBundleService()
+	if err != nil {
+		return err
+	}
+	bundle, err := bundles.ExportBundle(&dto.BundleExportQuery{Include: *include, PromptName: *promptName})
+	if err != nil {
+		return err
+	}
+	data, err := service.EncodeBundle(bundle, *format)
+	if err != nil {
+		return err
+	}
+
+	if *output == "" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	if err := os.WriteFile(*output, data, 0o644); err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Exported %d prompt versions, %d channels and %d problems to %s\n", len(bundle.Prompts), len(bundle.Channels), len(bundle.Problems), *output)
+	return nil
+}
+
+func importBundleCommand(args []string) error {
+	flags := flag.NewFlagSet("bundle import", flag.ContinueOnError)
+	policy := flags.String("policy", dto.BundlePolicySkip, "what to do with items that differ from the stored ones: skip, overwrite or new-version")
+	dryRun := flags.Bool("dry-run", false, "report the changes without applying them")
+	format := flags.String("format", "", "yaml or json, defaults to the file extension, then yaml")
+	actor := flags.String("actor", "cli", "who to record in the release history of moved channels")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() > 1 {
+		return fmt.Errorf("expected at most one bundle file, got %d", flags.NArg())
+	}
+
+	var data []byte
+	var err error
+	if path := flags.Arg(0); path != "" && path != "-" {
+		data, err = os.ReadFile(path)
+		if *format == "" {
+			*format = bundleFormatFromPath(path)
+		}
+	} else {
+		data, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		return err
+	}
+	if *format == "" {
+		*format = service.BundleFormatYAML
+	}
+	// YAML also reads JSON, but JSON is decoded strictly when it is named
+	bundle, err := service.DecodeBundle(data, *format)
+	if err != nil {
+		return err
+	}
+
+	bundles, err := newBundleService()
+	if err != nil {
+		return err
+	}
+	result, err := bundles.ImportBundle(bundle, &dto.BundleImportOptions{Policy: *policy, DryRun: *dryRun}, *actor)
+	if err != nil {
+		return err
+	}
+	printBundleResult(os.Stdout, result)
+	return nil
+}
+
+// printBundleResult writes one line per change followed by the totals.
+func printBundleResult(w io.Writer, result *dto.BundleImportResult) {
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "KIND\tKEY\tACTION\tDETAILS")
+	for _, change := range result.Changes {
+		var details []string
+		if change.From != "" && change.From != change.Target {
+			details = append(details, fmt.Sprintf("%s -> %s", change.From, change.Target))
+		} else if change.Target != "" && change.Kind != "channel" {
+			details = append(details, "as "+change.Target)
+		}
+		if len(change.Fields) > 0 {
+			details = append(details, "differs in "+strings.Join(change.Fields, ", "))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", change.Kind, change.Key, change.Action, strings.Join(details, "; "))
+	}
+	tw.Flush()
+
+	summary, _ := json.Marshal(result.Summary)
+	if result.DryRun {
+		fmt.Fprintf(w, "\nDry run with policy %s, nothing was imported: %s\n", result.Policy, summary)
+		return
+	}
+	fmt.Fprintf(w, "\nImported with policy %s: %s\n", result.Policy, summary)
+}
+
+// bundleFormatFromPath guesses the bundle format from a file extension.
+func bundleFormatFromPath(path string) string {
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".json":
+		return service.BundleFormatJSON
+	case ".yaml", ".yml":
+		return service.BundleFormatYAML
+	}
+	return ""
+}
@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"net/http"
+	"os"
 	"time"
 
 	"github.com/gin-contrib/cors"
@@ -14,11 +15,18 @@ import (
 
 	"minos/config"
 	"minos/database"
+	"minos/internal/auth"
+	"minos/internal/cache"
 	_ "minos/docs" // This will be created by swag
 	"minos/internal/controller"
-	"minos/internal/logger"
+	"minos/internal/llm"
 	"minos/internal/llm/gemini"
+	"minos/internal/llm/openai"
+	"minos/internal/logger"
+	"minos/internal/queue"
+	"minos/internal/ratelimit"
 	"minos/internal/repository"
+	"minos/internal/runner"
 	"minos/internal/service"
 	"minos/redis"
 )
@@ -35,13 +43,27 @@ import (
 // @BasePath /api/v1
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == runner.SandboxCommand {
+		os.Exit(runner.RunSandbox(os.Args[2:]))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "bundle" {
+		os.Exit(runBundleCommand(os.Args[2:]))
+	}
+
 	app := fx.New(
 		fx.Provide(
 			NewConfig,
 			database.NewDB,
 			redis.NewRedis,
 			NewGinEngine,
-			gemini.NewClient,
+			NewLLMProvider,
+			llm.NewPriceTable,
+			runner.NewRunner,
+			queue.NewRedisQueue,
+			cache.NewSessionCache,
+			ratelimit.NewRedisLimiter,
+			ratelimit.NewTokenBudget,
+			auth.NewJWTAuthenticator,
 			
 			// Repositories
 			repository.NewPromptTemplateRepository,
@@ -49,18 +71,38 @@ func main() {
 			repository.NewMessageRepository,
 			repository.NewSubmissionRepository,
 			repository.NewEvaluationRepository,
+			repository.NewExperimentRepository,
+			repository.NewAPIKeyRepository,
+			repository.NewUsageRepository,
+			repository.NewProblemRepository,
+			repository.NewBundleRepository,
 
 			// Services
 			service.NewService, // PromptTemplateService
+			service.NewPromptResolver,
 			service.NewInterviewService,
+			service.NewHistoryManager,
 			service.NewChatService,
+			service.NewSubmissionService,
+			service.NewExperimentService,
+			service.NewEvaluationService,
+			service.NewInterviewSweeper,
+			service.NewAPIKeyService,
+			service.NewUsageService,
+			service.NewProblemService,
+			service.NewBundleService,
 
 			// Controllers
 			controller.NewPromptTemplateController,
 			controller.NewInterviewController,
+			controller.NewExperimentController,
+			controller.NewAPIKeyController,
+			controller.NewUsageController,
+			controller.NewProblemController,
+			controller.NewBundleController,
 			controller.NewController,
 		),
-		fx.Invoke(RegisterRoutes),
+		fx.Invoke(RegisterRoutes, StartEvaluationWorkers, StartInterviewSweeper),
 	)
 
 	app.Run()
@@ -70,7 +112,33 @@ func NewConfig() (*config.Config, error) {
 	return config.NewConfig()
 }
 
-func NewGinEngine() *gin.Engine {
+// NewLLMProvider builds the ordered model chain: the OpenAI-compatible default
+// model, then FALLBACK_LLM_MODELS, then Gemini when a Gemini key is configured.
+func NewLLMProvider(cfg *config.Config) (llm.Provider, error) {
+	var providers []llm.Provider
+
+	if cfg.OpenAI.ApiKey != "" {
+		models := cfg.LLM.FallbackModels
+		if cfg.LLM.DefaultModel != "" {
+			models = append([]string{cfg.LLM.DefaultModel}, models...)
+		}
+		for _, m := range models {
+			providers = append(providers, openai.NewClient(cfg, m))
+		}
+	}
+
+	if cfg.Gemini.ApiKey != "" || len(providers) == 0 {
+		geminiClient, err := gemini.NewClient(cfg)
+		if err != nil {
+			return nil, err
+		}
+		providers = append(providers, geminiClient)
+	}
+
+	return llm.NewFallbackProvider(time.Duration(cfg.LLM.TimeoutSeconds)*time.Second, providers...), nil
+}
+
+func NewGinEngine(cfg *config.Config, authenticator auth.Authenticator, apiKeys service.APIKeyService) *gin.Engine {
 	gin.SetMode(gin.ReleaseMode)
 	r := gin.New()
 	r.Use(gin.Recovery())
@@ -79,8 +147,8 @@ func NewGinEngine() *gin.Engine {
 	r.Use(cors.New(cors.Config{
 		AllowOrigins:     []string{"*"}, // Add your frontend URLs
 		AllowMethods:     []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
-		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization"},
-		ExposeHeaders:    []string{"Content-Length"},
+		AllowHeaders:     []string{"Origin", "Content-Type", "Accept", "Authorization", auth.APIKeyHeader},
+		ExposeHeaders:    []string{"Content-Length", "Retry-After"},
 		AllowCredentials: true,
 		MaxAge:           12 * time.Hour,
 	}))
@@ -88,6 +156,10 @@ func NewGinEngine() *gin.Engine {
 	// Add swagger route
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
+	// Resolve the caller for every route; routes decide what they require
+	r.Use(auth.Middleware(authenticator, cfg.Auth.Enabled, cfg.Auth.AdminRole))
+	r.Use(auth.APIKeyMiddleware(apiKeys, cfg.Auth.Enabled))
+
 	return r
 }
 
@@ -121,3 +193,29 @@ func RegisterRoutes(
 		},
 	})
 }
+
+// StartEvaluationWorkers runs the evaluation queue consumers for the lifetime of the app.
+func StartEvaluationWorkers(lifecycle fx.Lifecycle, evaluations service.EvaluationService) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return evaluations.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			log.Info().Msg("Stopping evaluation workers")
+			return evaluations.Stop(ctx)
+		},
+	})
+}
+
+// StartInterviewSweeper abandons idle interviews in the background for the lifetime of the app.
+func StartInterviewSweeper(lifecycle fx.Lifecycle, sweeper service.InterviewSweeper) {
+	lifecycle.Append(fx.Hook{
+		OnStart: func(ctx context.Context) error {
+			return sweeper.Start()
+		},
+		OnStop: func(ctx context.Context) error {
+			log.Info().Msg("Stopping interview sweeper")
+			return sweeper.Stop(ctx)
+		},
+	})
+}
@@ -1,15 +1,29 @@
 package config
 
 import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/rs/zerolog/log"
 	"github.com/spf13/viper"
 )
 
 type Config struct {
-	Server   ServerConfig
-	Database DatabaseConfig
-	Redis    RedisConfig
-	Gemini   GeminiConfig
+	Server     ServerConfig
+	Database   DatabaseConfig
+	Redis      RedisConfig
+	Gemini     GeminiConfig
+	OpenAI     OpenAIConfig
+	LLM        LLMConfig
+	Runner     RunnerConfig
+	Evaluation EvaluationConfig
+	Session    SessionConfig
+	Context    ContextConfig
+	Interview  InterviewConfig
+	Auth       AuthConfig
+	RateLimit  RateLimitConfig
 }
 
 type ServerConfig struct {
@@ -21,22 +35,106 @@ type DatabaseConfig struct {
 	Host     string
 	Port     string
 	User     string
-	Password string
+	Password string `json:"-"`
 	Name     string
 }
 
 type RedisConfig struct {
 	Host     string
 	Port     string
-	Password string
+	Password string `json:"-"`
 	DB       int
 }
 
 type GeminiConfig struct {
-	ApiKey string
+	ApiKey string `json:"-"`
 	Model  string
 }
 
+type OpenAIConfig struct {
+	ApiKey       string `json:"-"`
+	BaseURL      string
+	ProviderSort string // OpenRouter provider routing, e.g. "latency" or "price"
+}
+
+// ModelPrice is the price of a model in USD per million tokens.
+type ModelPrice struct {
+	Input  float64 `json:"input"`
+	Output float64 `json:"output"`
+}
+
+type LLMConfig struct {
+	DefaultModel   string
+	FallbackModels []string
+	TimeoutSeconds int // Per-attempt timeout before moving to the next model
+	// StructuredMaxAttempts is how many times a structured call is asked
+	// before its reply is given up on as invalid
+	StructuredMaxAttempts int
+	Prices                map[string]ModelPrice // Keyed by model name or name prefix
+}
+
+type RunnerConfig struct {
+	Enabled               bool
+	WorkDir               string // Parent of the per-run temp dirs, defaults to the OS temp dir
+	TimeoutSeconds        int    // Wall-clock limit per test case
+	CompileTimeoutSeconds int
+	MemoryLimitMB         int
+	CompileMemoryLimitMB  int
+	MaxProcesses          int // Process and thread limit, shared by everything running as UID
+	UID                   int // Unprivileged user code runs as; the service must start as root to switch to it
+	GID                   int
+	Mounts                []string // Host paths exposed read-only in the sandbox besides the system directories
+	IsolateNetwork        bool     // Run code in a fresh network namespace
+}
+
+type EvaluationConfig struct {
+	Workers           int // Concurrent evaluation jobs per instance
+	MaxAttempts       int // Job attempts before the evaluation is marked failed
+	BackoffSeconds    int // Delay before the first retry, doubled on every further attempt
+	MaxBackoffSeconds int
+}
+
+type SessionConfig struct {
+	TTLMinutes int // Idle time after which a cached interview session expires
+	MaxTurns   int // Most recent messages kept in the cached session
+}
+
+type ContextConfig struct {
+	MaxTokens      int // Estimated tokens of instructions and history sent per chat turn
+	KeepTurns      int // Most recent turns always sent verbatim
+	SummarizeBatch int // Older turns that must pile up before they are folded into the summary
+}
+
+type InterviewConfig struct {
+	IdleTimeoutMinutes   int  // Active interviews without messages for this long are abandoned
+	PausedTimeoutMinutes int  // Interviews paused for this long are abandoned
+	SweepIntervalSeconds int  // How often to look for idle interviews
+	EvaluateAbandoned    bool // Queue a partial evaluation for abandoned interviews
+	MaxActivePerUser     int  // Concurrent active or paused interviews a user may have, 0 for no limit
+	DurationMinutes      int  // Default time budget of an interview, 0 for untimed
+	// Points taken off the problem solving and overall scores for the hint
+	// at each level, nudge first; levels past the end of the list are free
+	HintPenalties []int
+}
+
+type AuthConfig struct {
+	Enabled    bool   // When false every request runs as an admin
+	JWTSecret  string `json:"-"` // HS256 signing secret
+	JWKSFile   string // JSON Web Key Set with the RS256 public keys
+	Issuer     string // Expected iss claim, unchecked when empty
+	Audience   string // Expected aud claim, unchecked when empty
+	RolesClaim string // Claim listing the user's roles
+	AdminRole  string
+}
+
+type RateLimitConfig struct {
+	Enabled          bool
+	WindowSeconds    int   // Length of the sliding window
+	UserRequests     int   // Requests a user may make per window, 0 for no limit
+	KeyRequests      int   // Requests an API key may make per window, 0 for no limit
+	DailyTokenBudget int64 // LLM tokens a user may spend per UTC day, 0 for no budget
+}
+
 func NewConfig() (*Config, error) {
 	// Configure Viper to read .env file
 	viper.SetConfigName(".env")
@@ -68,7 +166,182 @@ func NewConfig() (*Config, error) {
 	config.Redis.DB = viper.GetInt("REDIS_DB")
 	config.Gemini.ApiKey = viper.GetString("GEMINI_API_KEY")
 	config.Gemini.Model = viper.GetString("GEMINI_MODEL")
+	config.OpenAI.ApiKey = viper.GetString("OPENAI_API_KEY")
+	config.OpenAI.BaseURL = viper.GetString("OPENAI_BASE_URL")
+	if config.OpenAI.BaseURL == "" {
+		config.OpenAI.BaseURL = "https://openrouter.ai/api/v1"
+	}
+	config.OpenAI.ProviderSort = viper.GetString("OPEN_ROUTER_PROVIDER_SORT")
+	config.LLM.DefaultModel = viper.GetString("DEFAULT_LLM_MODEL")
+	config.LLM.FallbackModels = parseList(viper.GetString("FALLBACK_LLM_MODELS"))
+	config.LLM.TimeoutSeconds = viper.GetInt("LLM_TIMEOUT_SECONDS")
+	if config.LLM.TimeoutSeconds <= 0 {
+		config.LLM.TimeoutSeconds = 60
+	}
+	config.LLM.StructuredMaxAttempts = viper.GetInt("LLM_STRUCTURED_MAX_ATTEMPTS")
+	if config.LLM.StructuredMaxAttempts <= 0 {
+		config.LLM.StructuredMaxAttempts = 3
+	}
+	if raw := strings.TrimSpace(viper.GetString("LLM_PRICES")); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &config.LLM.Prices); err != nil {
+			return nil, fmt.Errorf("invalid LLM_PRICES: %w", err)
+		}
+	}
+
+	viper.SetDefault("RUNNER_ENABLED", true)
+	viper.SetDefault("RUNNER_ISOLATE_NETWORK", true)
+	config.Runner.Enabled = viper.GetBool("RUNNER_ENABLED")
+	config.Runner.WorkDir = viper.GetString("RUNNER_WORK_DIR")
+	config.Runner.TimeoutSeconds = viper.GetInt("RUNNER_TIMEOUT_SECONDS")
+	if config.Runner.TimeoutSeconds <= 0 {
+		config.Runner.TimeoutSeconds = 5
+	}
+	config.Runner.CompileTimeoutSeconds = viper.GetInt("RUNNER_COMPILE_TIMEOUT_SECONDS")
+	if config.Runner.CompileTimeoutSeconds <= 0 {
+		config.Runner.CompileTimeoutSeconds = 60
+	}
+	config.Runner.MemoryLimitMB = viper.GetInt("RUNNER_MEMORY_LIMIT_MB")
+	if config.Runner.MemoryLimitMB <= 0 {
+		config.Runner.MemoryLimitMB = 512
+	}
+	config.Runner.CompileMemoryLimitMB = viper.GetInt("RUNNER_COMPILE_MEMORY_LIMIT_MB")
+	if config.Runner.CompileMemoryLimitMB <= 0 {
+		config.Runner.CompileMemoryLimitMB = 2048
+	}
+	config.Runner.MaxProcesses = viper.GetInt("RUNNER_MAX_PROCESSES")
+	if config.Runner.MaxProcesses <= 0 {
+		config.Runner.MaxProcesses = 128
+	}
+	// Root is never a valid sandbox user
+	config.Runner.UID = viper.GetInt("RUNNER_UID")
+	if config.Runner.UID <= 0 {
+		config.Runner.UID = 65534
+	}
+	config.Runner.GID = viper.GetInt("RUNNER_GID")
+	if config.Runner.GID <= 0 {
+		config.Runner.GID = 65534
+	}
+	config.Runner.Mounts = parseList(viper.GetString("RUNNER_MOUNTS"))
+	config.Runner.IsolateNetwork = viper.GetBool("RUNNER_ISOLATE_NETWORK")
+
+	config.Evaluation.Workers = viper.GetInt("EVALUATION_WORKERS")
+	if config.Evaluation.Workers <= 0 {
+		config.Evaluation.Workers = 2
+	}
+	config.Evaluation.MaxAttempts = viper.GetInt("EVALUATION_MAX_ATTEMPTS")
+	if config.Evaluation.MaxAttempts <= 0 {
+		config.Evaluation.MaxAttempts = 5
+	}
+	config.Evaluation.BackoffSeconds = viper.GetInt("EVALUATION_BACKOFF_SECONDS")
+	if config.Evaluation.BackoffSeconds <= 0 {
+		config.Evaluation.BackoffSeconds = 10
+	}
+	config.Evaluation.MaxBackoffSeconds = viper.GetInt("EVALUATION_MAX_BACKOFF_SECONDS")
+	if config.Evaluation.MaxBackoffSeconds <= 0 {
+		config.Evaluation.MaxBackoffSeconds = 600
+	}
+
+	config.Session.TTLMinutes = viper.GetInt("SESSION_TTL_MINUTES")
+	if config.Session.TTLMinutes <= 0 {
+		config.Session.TTLMinutes = 30
+	}
+	config.Session.MaxTurns = viper.GetInt("SESSION_MAX_TURNS")
+	if config.Session.MaxTurns <= 0 {
+		config.Session.MaxTurns = 100
+	}
+
+	config.Context.MaxTokens = viper.GetInt("CONTEXT_MAX_TOKENS")
+	if config.Context.MaxTokens <= 0 {
+		config.Context.MaxTokens = 16000
+	}
+	config.Context.KeepTurns = viper.GetInt("CONTEXT_KEEP_TURNS")
+	if config.Context.KeepTurns <= 0 {
+		config.Context.KeepTurns = 10
+	}
+	config.Context.SummarizeBatch = viper.GetInt("CONTEXT_SUMMARIZE_BATCH")
+	if config.Context.SummarizeBatch <= 0 {
+		config.Context.SummarizeBatch = 6
+	}
+
+	viper.SetDefault("INTERVIEW_EVALUATE_ABANDONED", true)
+	viper.SetDefault("INTERVIEW_MAX_ACTIVE_PER_USER", 3)
+	config.Interview.IdleTimeoutMinutes = viper.GetInt("INTERVIEW_IDLE_TIMEOUT_MINUTES")
+	if config.Interview.IdleTimeoutMinutes <= 0 {
+		config.Interview.IdleTimeoutMinutes = 60
+	}
+	config.Interview.PausedTimeoutMinutes = viper.GetInt("INTERVIEW_PAUSED_TIMEOUT_MINUTES")
+	if config.Interview.PausedTimeoutMinutes <= 0 {
+		config.Interview.PausedTimeoutMinutes = 24 * 60
+	}
+	config.Interview.SweepIntervalSeconds = viper.GetInt("INTERVIEW_SWEEP_INTERVAL_SECONDS")
+	if config.Interview.SweepIntervalSeconds <= 0 {
+		config.Interview.SweepIntervalSeconds = 60
+	}
+	config.Interview.EvaluateAbandoned = viper.GetBool("INTERVIEW_EVALUATE_ABANDONED")
+	config.Interview.MaxActivePerUser = viper.GetInt("INTERVIEW_MAX_ACTIVE_PER_USER")
+	config.Interview.DurationMinutes = viper.GetInt("INTERVIEW_DURATION_MINUTES")
+	viper.SetDefault("INTERVIEW_HINT_PENALTIES", "1,1,2")
+	for _, item := range parseList(viper.GetString("INTERVIEW_HINT_PENALTIES")) {
+		penalty, err := strconv.Atoi(item)
+		if err != nil || penalty < 0 {
+			return nil, fmt.Errorf("invalid INTERVIEW_HINT_PENALTIES: %q is not a non-negative integer", item)
+		}
+		config.Interview.HintPenalties = append(config.Interview.HintPenalties, penalty)
+	}
+
+	viper.SetDefault("AUTH_ENABLED", true)
+	config.Auth.Enabled = viper.GetBool("AUTH_ENABLED")
+	config.Auth.JWTSecret = viper.GetString("JWT_SECRET")
+	config.Auth.JWKSFile = viper.GetString("JWT_JWKS_FILE")
+	config.Auth.Issuer = viper.GetString("JWT_ISSUER")
+	config.Auth.Audience = viper.GetString("JWT_AUDIENCE")
+	config.Auth.RolesClaim = viper.GetString("JWT_ROLES_CLAIM")
+	if config.Auth.RolesClaim == "" {
+		config.Auth.RolesClaim = "roles"
+	}
+	config.Auth.AdminRole = viper.GetString("AUTH_ADMIN_ROLE")
+	if config.Auth.AdminRole == "" {
+		config.Auth.AdminRole = "admin"
+	}
+
+	viper.SetDefault("RATE_LIMIT_ENABLED", true)
+	viper.SetDefault("RATE_LIMIT_USER_REQUESTS", 60)
+	viper.SetDefault("RATE_LIMIT_KEY_REQUESTS", 600)
+	viper.SetDefault("TOKEN_BUDGET_DAILY", 500000)
+	config.RateLimit.Enabled = viper.GetBool("RATE_LIMIT_ENABLED")
+	config.RateLimit.WindowSeconds = viper.GetInt("RATE_LIMIT_WINDOW_SECONDS")
+	if config.RateLimit.WindowSeconds <= 0 {
+		config.RateLimit.WindowSeconds = 60
+	}
+	config.RateLimit.UserRequests = viper.GetInt("RATE_LIMIT_USER_REQUESTS")
+	config.RateLimit.KeyRequests = viper.GetInt("RATE_LIMIT_KEY_REQUESTS")
+	config.RateLimit.DailyTokenBudget = viper.GetInt64("TOKEN_BUDGET_DAILY")
 
 	log.Info().Interface("config", config).Msg("Config loaded")
 	return &config, nil
 }
+
+// parseList accepts either a JSON array or a comma-separated string.
+func parseList(raw string) []string {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil
+	}
+
+	var list []string
+	if strings.HasPrefix(raw, "[") {
+		if err := json.Unmarshal([]byte(raw), &list); err == nil {
+			return list
+		}
+		log.Warn().Str("value", raw).Msg("Invalid JSON list, falling back to comma-separated parsing")
+		raw = strings.Trim(raw, "[]")
+	}
+
+	for _, item := range strings.Split(raw, ",") {
+		item = strings.Trim(strings.TrimSpace(item), `"`)
+		if item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
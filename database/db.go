@@ -35,6 +35,12 @@ func NewDB(cfg *config.Config) (*gorm.DB, error) {
 		&model.Message{},
 		&model.Submission{},
 		&model.Evaluation{},
+		&model.PromptChannel{},
+		&model.PromptChannelHistory{},
+		&model.Experiment{},
+		&model.ExperimentArm{},
+		&model.APIKey{},
+		&model.Problem{},
 	); err != nil {
 		return nil, fmt.Errorf("failed to auto migrate: %w", err)
 	}
@@ -44,6 +50,18 @@ func NewDB(cfg *config.Config) (*gorm.DB, error) {
 	if err != nil {
 		return nil, fmt.Errorf("failed to create unique index: %w", err)
 	}
+	err = db.Exec("CREATE UNIQUE INDEX IF NOT EXISTS idx_problems_slug ON problems(slug) WHERE deleted_at IS NULL").Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to create unique index: %w", err)
+	}
+
+	// Snapshots used to carry the hidden test cases; move them out of sight
+	err = db.Exec(`UPDATE interviews
+		SET hidden_test_cases = problem_snapshot->'hidden_test_cases', problem_snapshot = problem_snapshot - 'hidden_test_cases'
+		WHERE jsonb_typeof(problem_snapshot) = 'object' AND problem_snapshot->'hidden_test_cases' IS NOT NULL`).Error
+	if err != nil {
+		return nil, fmt.Errorf("failed to move hidden test cases out of snapshots: %w", err)
+	}
 
 	return db, nil
 }
@@ -0,0 +1,177 @@
+package auth
+
+import (
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"minos/config"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Authenticator turns a bearer token into a Principal.
+type Authenticator interface {
+	Authenticate(token string) (*Principal, error)
+}
+
+type jwtAuthenticator struct {
+	secret     []byte
+	keys       map[string]*rsa.PublicKey // RS256 keys from the JWKS file, by kid
+	methods    []string
+	issuer     string
+	audience   string
+	rolesClaim string
+	adminRole  string
+}
+
+// NewJWTAuthenticator verifies HS256 tokens with JWT_SECRET and RS256 tokens
+// with the keys in JWT_JWKS_FILE; at least one must be configured. The
+// user is taken from the sub claim, which must be a UUID.
+func NewJWTAuthenticator(cfg *config.Config) (Authenticator, error) {
+	a := &jwtAuthenticator{
+		issuer:     cfg.Auth.Issuer,
+		audience:   cfg.Auth.Audience,
+		rolesClaim: cfg.Auth.RolesClaim,
+		adminRole:  cfg.Auth.AdminRole,
+	}
+	if cfg.Auth.JWTSecret != "" {
+		a.secret = []byte(cfg.Auth.JWTSecret)
+		a.methods = append(a.methods, jwt.SigningMethodHS256.Alg())
+	}
+	if cfg.Auth.JWKSFile != "" {
+		keys, err := loadJWKS(cfg.Auth.JWKSFile)
+		if err != nil {
+			return nil, err
+		}
+		a.keys = keys
+		a.methods = append(a.methods, jwt.SigningMethodRS256.Alg())
+	}
+	if cfg.Auth.Enabled && len(a.methods) == 0 {
+		return nil, errors.New("auth is enabled but neither JWT_SECRET nor JWT_JWKS_FILE is set")
+	}
+
+	log.Info().Bool("enabled", cfg.Auth.Enabled).Strs("methods", a.methods).Int("jwks_keys", len(a.keys)).Msg("JWT authenticator initialized")
+	return a, nil
+}
+
+func (a *jwtAuthenticator) Authenticate(token string) (*Principal, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithValidMethods(a.methods),
+		jwt.WithExpirationRequired(),
+	}
+	if a.issuer != "" {
+		opts = append(opts, jwt.WithIssuer(a.issuer))
+	}
+	if a.audience != "" {
+		opts = append(opts, jwt.WithAudience(a.audience))
+	}
+
+	claims := jwt.MapClaims{}
+	if _, err := jwt.ParseWithClaims(token, claims, a.key, opts...); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnauthenticated, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrUnauthenticated)
+	}
+	userID, err := uuid.Parse(subject)
+	if err != nil {
+		return nil, fmt.Errorf("%w: subject is not a user id", ErrUnauthenticated)
+	}
+
+	return &Principal{
+		UserID:    userID,
+		Subject:   subject,
+		Roles:     stringsClaim(claims[a.rolesClaim]),
+		AdminRole: a.adminRole,
+	}, nil
+}
+
+// key picks the verification key for the token's algorithm and kid.
+func (a *jwtAuthenticator) key(token *jwt.Token) (interface{}, error) {
+	switch token.Method.Alg() {
+	case jwt.SigningMethodHS256.Alg():
+		return a.secret, nil
+	case jwt.SigningMethodRS256.Alg():
+		kid, _ := token.Header["kid"].(string)
+		if key, ok := a.keys[kid]; ok {
+			return key, nil
+		}
+		if kid == "" && len(a.keys) == 1 {
+			for _, key := range a.keys {
+				return key, nil
+			}
+		}
+		return nil, fmt.Errorf("unknown key id '%s'", kid)
+	}
+	return nil, fmt.Errorf("unexpected signing method %s", token.Method.Alg())
+}
+
+// stringsClaim accepts a JSON array of strings or a space/comma separated string.
+func stringsClaim(value interface{}) []string {
+	var out []string
+	switch v := value.(type) {
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				out = append(out, s)
+			}
+		}
+	case string:
+		out = strings.FieldsFunc(v, func(r rune) bool { return r == ' ' || r == ',' })
+	}
+	return out
+}
+
+type jwks struct {
+	Keys []struct {
+		Kty string `json:"kty"`
+		Kid string `json:"kid"`
+		Use string `json:"use"`
+		N   string `json:"n"`
+		E   string `json:"e"`
+	} `json:"keys"`
+}
+
+// loadJWKS reads the RSA signing keys of a JSON Web Key Set file.
+func loadJWKS(path string) (map[string]*rsa.PublicKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read JWKS file: %w", err)
+	}
+	var set jwks
+	if err := json.Unmarshal(raw, &set); err != nil {
+		return nil, fmt.Errorf("invalid JWKS file: %w", err)
+	}
+
+	keys := make(map[string]*rsa.PublicKey)
+	for _, k := range set.Keys {
+		if k.Kty != "RSA" || (k.Use != "" && k.Use != "sig") {
+			continue
+		}
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid modulus for key '%s': %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid exponent for key '%s': %w", k.Kid, err)
+		}
+		keys[k.Kid] = &rsa.PublicKey{
+			N: new(big.Int).SetBytes(n),
+			E: int(new(big.Int).SetBytes(e).Int64()),
+		}
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("JWKS file contains no RSA signing keys")
+	}
+	return keys, nil
+}
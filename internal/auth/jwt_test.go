@@ -0,0 +1,178 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"minos/config"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+)
+
+func TestStringsClaim(t *testing.T) {
+	tests := []struct {
+		name  string
+		value interface{}
+		want  []string
+	}{
+		{"array", []interface{}{"admin", "reviewer"}, []string{"admin", "reviewer"}},
+		{"array skips non-strings", []interface{}{"admin", 1, "", nil, "reviewer"}, []string{"admin", "reviewer"}},
+		{"space separated", "admin reviewer", []string{"admin", "reviewer"}},
+		{"comma separated", "admin,reviewer, ops", []string{"admin", "reviewer", "ops"}},
+		{"empty string", "", []string{}},
+		{"missing", nil, nil},
+		{"other type", 42.0, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := stringsClaim(tt.value); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("stringsClaim(%#v) = %#v, want %#v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+// jwk encodes the public half of key as a JSON Web Key.
+func jwk(kid, use string, key *rsa.PrivateKey) map[string]string {
+	return map[string]string{
+		"kty": "RSA",
+		"kid": kid,
+		"use": use,
+		"n":   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+		"e":   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+	}
+}
+
+func writeJWKS(t *testing.T, keys ...interface{}) string {
+	t.Helper()
+	raw, err := json.Marshal(map[string]interface{}{"keys": keys})
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, raw, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func generateKey(t *testing.T) *rsa.PrivateKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+func TestLoadJWKS(t *testing.T) {
+	signing, other := generateKey(t), generateKey(t)
+	path := writeJWKS(t,
+		jwk("signing", "sig", signing),
+		jwk("unlabelled", "", other),
+		jwk("encryption", "enc", other),
+		map[string]string{"kty": "EC", "kid": "ec", "crv": "P-256"},
+	)
+
+	keys, err := loadJWKS(path)
+	if err != nil {
+		t.Fatalf("loadJWKS: %v", err)
+	}
+	if len(keys) != 2 {
+		t.Fatalf("loadJWKS loaded %d keys, want 2", len(keys))
+	}
+	if !signing.PublicKey.Equal(keys["signing"]) {
+		t.Errorf("key 'signing' does not match the generated key")
+	}
+	if !other.PublicKey.Equal(keys["unlabelled"]) {
+		t.Errorf("key 'unlabelled' does not match the generated key")
+	}
+}
+
+func TestLoadJWKSErrors(t *testing.T) {
+	key := generateKey(t)
+	badModulus := jwk("bad", "sig", key)
+	badModulus["n"] = "not base64!"
+
+	tests := map[string]string{
+		"missing file": filepath.Join(t.TempDir(), "missing.json"),
+		"not json":     writeRaw(t, "keys"),
+		"no rsa keys":  writeJWKS(t, jwk("encryption", "enc", key)),
+		"bad modulus":  writeJWKS(t, badModulus),
+	}
+	for name, path := range tests {
+		t.Run(name, func(t *testing.T) {
+			if _, err := loadJWKS(path); err == nil {
+				t.Error("loadJWKS succeeded, want an error")
+			}
+		})
+	}
+}
+
+func writeRaw(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestAuthenticateWithJWKS(t *testing.T) {
+	key := generateKey(t)
+	a, err := NewJWTAuthenticator(&config.Config{Auth: config.AuthConfig{
+		Enabled:    true,
+		JWKSFile:   writeJWKS(t, jwk("k1", "sig", key)),
+		RolesClaim: "roles",
+		AdminRole:  "admin",
+	}})
+	if err != nil {
+		t.Fatalf("NewJWTAuthenticator: %v", err)
+	}
+
+	userID := uuid.New()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, jwt.MapClaims{
+		"sub":   userID.String(),
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"roles": []string{"admin"},
+	})
+	token.Header["kid"] = "k1"
+	signed, err := token.SignedString(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	principal, err := a.Authenticate(signed)
+	if err != nil {
+		t.Fatalf("Authenticate: %v", err)
+	}
+	if principal.UserID != userID || !reflect.DeepEqual(principal.Roles, []string{"admin"}) {
+		t.Errorf("Authenticate = %+v, want user %s with role admin", principal, userID)
+	}
+
+	forged, err := token.SignedString(generateKey(t))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := a.Authenticate(forged); err == nil {
+		t.Error("Authenticate accepted a token signed with an unknown key")
+	}
+}
+
+func TestNewJWTAuthenticatorRequiresAKey(t *testing.T) {
+	if _, err := NewJWTAuthenticator(&config.Config{Auth: config.AuthConfig{Enabled: true}}); err == nil {
+		t.Error("NewJWTAuthenticator succeeded with auth enabled and neither a secret nor a JWKS file")
+	}
+	if _, err := NewJWTAuthenticator(&config.Config{Auth: config.AuthConfig{Enabled: false}}); err != nil {
+		t.Errorf("NewJWTAuthenticator with auth disabled: %v", err)
+	}
+}
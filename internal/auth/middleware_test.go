@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name    string
+		url     string
+		headers map[string]string
+		want    string
+	}{
+		{"header", "/interviews", map[string]string{"Authorization": "Bearer abc"}, "abc"},
+		{"header case", "/interviews", map[string]string{"Authorization": "bearer abc "}, "abc"},
+		{"query on a REST call", "/interviews?access_token=abc", nil, ""},
+		{"query on a WebSocket upgrade", "/interviews/1/ws?access_token=abc", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket"}, "abc"},
+		{"header wins on a WebSocket upgrade", "/interviews/1/ws?access_token=abc", map[string]string{"Connection": "Upgrade", "Upgrade": "websocket", "Authorization": "Bearer def"}, "def"},
+		{"none", "/interviews", nil, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, _ := gin.CreateTestContext(httptest.NewRecorder())
+			ctx.Request = httptest.NewRequest(http.MethodGet, tt.url, nil)
+			for k, v := range tt.headers {
+				ctx.Request.Header.Set(k, v)
+			}
+			if got := bearerToken(ctx); got != tt.want {
+				t.Errorf("bearerToken = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,95 @@
+package auth
+
+import (
+	"errors"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// ErrUnauthenticated is returned when credentials are missing, malformed,
+// expired or signed with an unknown key.
+var ErrUnauthenticated = errors.New("unauthenticated")
+
+// Scopes an API key can be granted. They only restrict API key clients;
+// users are governed by their roles and by resource ownership.
+const (
+	ScopeInterviewsWrite = "interviews:write"
+	ScopePromptsAdmin    = "prompts:admin"
+	ScopeEvaluationsRead = "evaluations:read"
+	ScopeProblemsAdmin   = "problems:admin"
+)
+
+// Scopes lists every valid scope.
+var Scopes = []string{ScopeInterviewsWrite, ScopePromptsAdmin, ScopeEvaluationsRead, ScopeProblemsAdmin}
+
+// principalKey is the gin context key holding the authenticated Principal.
+const principalKey = "auth.principal"
+
+// Principal is the caller of a request as established by the auth middleware.
+type Principal struct {
+	UserID  uuid.UUID `json:"user_id"`
+	Subject string    `json:"subject"`
+	Roles   []string  `json:"roles,omitempty"`
+	// AdminRole is the role that grants access to every resource
+	AdminRole string `json:"-"`
+
+	// Set when the caller authenticated with an API key
+	APIKeyID *uuid.UUID `json:"api_key_id,omitempty"`
+	Scopes   []string   `json:"scopes,omitempty"`
+}
+
+func (p *Principal) HasRole(role string) bool {
+	for _, r := range p.Roles {
+		if r == role {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *Principal) IsAdmin() bool {
+	return p.AdminRole != "" && p.HasRole(p.AdminRole)
+}
+
+// IsService reports whether the caller is a machine client using an API key.
+func (p *Principal) IsService() bool {
+	return p.APIKeyID != nil
+}
+
+// HasScope reports whether an API key principal was granted any of scopes.
+func (p *Principal) HasScope(scopes ...string) bool {
+	for _, granted := range p.Scopes {
+		for _, scope := range scopes {
+			if granted == scope {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// ActsForAnyUser reports whether the principal is not limited to its own
+// resources: admins, and service clients whose access is bounded by scopes.
+func (p *Principal) ActsForAnyUser() bool {
+	return p.IsAdmin() || p.IsService()
+}
+
+// CanAccessUser reports whether the principal may act on resources owned by userID.
+func (p *Principal) CanAccessUser(userID uuid.UUID) bool {
+	return p.ActsForAnyUser() || (p.UserID != uuid.Nil && p.UserID == userID)
+}
+
+// FromContext returns the principal of the request, or nil when it is anonymous.
+func FromContext(ctx *gin.Context) *Principal {
+	value, ok := ctx.Get(principalKey)
+	if !ok {
+		return nil
+	}
+	principal, _ := value.(*Principal)
+	return principal
+}
+
+func setPrincipal(ctx *gin.Context, principal *Principal) {
+	ctx.Set(principalKey, principal)
+}
@@ -0,0 +1,189 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"minos/config"
+	"minos/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Session is the short-lived state of an active interview kept in Redis so a
+// chat turn does not have to reload the interview and its full history.
+type Session struct {
+	InterviewID      uuid.UUID             `json:"interview_id"`
+	UserID           uuid.UUID             `json:"user_id"`
+	Status           model.InterviewStatus `json:"status"`
+	PromptTemplateID *uint                 `json:"prompt_template_id,omitempty"`
+	SystemPrompt     string                `json:"system_prompt"` // Rendered interviewer prompt
+	ProblemSnapshot  json.RawMessage       `json:"problem_snapshot"`
+	DurationMinutes  int                   `json:"duration_minutes,omitempty"`
+	ActiveSeconds    int64                 `json:"active_seconds,omitempty"`
+	ResumedAt        *time.Time            `json:"resumed_at,omitempty"`
+	Turns            []Turn                `json:"-"` // Most recent messages, oldest first
+
+	// Transcript summary state, see model.Interview
+	TranscriptSummary string                 `json:"transcript_summary,omitempty"`
+	SummarizedThrough *uuid.UUID             `json:"summarized_through,omitempty"`
+	PreservedCode     []model.CodeAttachment `json:"preserved_code,omitempty"`
+}
+
+// Turn is one cached message of the conversation.
+type Turn struct {
+	ID      uuid.UUID         `json:"id"`
+	Role    model.MessageRole `json:"role"`
+	Content string            `json:"content"`
+}
+
+func TurnFromMessage(msg *model.Message) Turn {
+	return Turn{ID: msg.ID, Role: msg.Role, Content: msg.Content}
+}
+
+// SessionCache stores sessions under minos:session:<id> (metadata) and
+// minos:session:<id>:turns (a capped list of turns). Both keys expire after
+// the configured idle time; every read or write pushes the expiry back.
+type SessionCache interface {
+	// Get returns the cached session, or nil when there is none.
+	Get(ctx context.Context, interviewID uuid.UUID) (*Session, error)
+	// Set replaces the whole session, turns included.
+	Set(ctx context.Context, session *Session) error
+	// AppendTurns adds turns to a cached session. It is a no-op when the
+	// session is not cached, so a partial history is never created.
+	AppendTurns(ctx context.Context, interviewID uuid.UUID, turns ...Turn) error
+	// SetMeta replaces the session's metadata and leaves its turns alone. It
+	// is a no-op when the session is not cached.
+	SetMeta(ctx context.Context, session *Session) error
+	// Delete invalidates the session.
+	Delete(ctx context.Context, interviewID uuid.UUID) error
+}
+
+type sessionCache struct {
+	rdb      *redis.Client
+	ttl      time.Duration
+	maxTurns int
+}
+
+func NewSessionCache(rdb *redis.Client, cfg *config.Config) SessionCache {
+	return &sessionCache{
+		rdb:      rdb,
+		ttl:      time.Duration(cfg.Session.TTLMinutes) * time.Minute,
+		maxTurns: cfg.Session.MaxTurns,
+	}
+}
+
+func sessionKey(id uuid.UUID) string { return "minos:session:" + id.String() }
+func turnsKey(id uuid.UUID) string   { return "minos:session:" + id.String() + ":turns" }
+
+// appendScript pushes turns only while the session exists, then trims the
+// list and refreshes both expiries.
+var appendScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 0 then
+	return 0
+end
+for i = 3, #ARGV do
+	redis.call('RPUSH', KEYS[2], ARGV[i])
+end
+redis.call('LTRIM', KEYS[2], -tonumber(ARGV[2]), -1)
+redis.call('PEXPIRE', KEYS[1], ARGV[1])
+redis.call('PEXPIRE', KEYS[2], ARGV[1])
+return 1
+`)
+
+func (c *sessionCache) Get(ctx context.Context, interviewID uuid.UUID) (*Session, error) {
+	pipe := c.rdb.TxPipeline()
+	metaCmd := pipe.Get(ctx, sessionKey(interviewID))
+	turnsCmd := pipe.LRange(ctx, turnsKey(interviewID), 0, -1)
+	pipe.PExpire(ctx, sessionKey(interviewID), c.ttl)
+	pipe.PExpire(ctx, turnsKey(interviewID), c.ttl)
+	if _, err := pipe.Exec(ctx); err != nil && !errors.Is(err, redis.Nil) {
+		return nil, err
+	}
+
+	raw, err := metaCmd.Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var session Session
+	if err := json.Unmarshal([]byte(raw), &session); err != nil {
+		return nil, fmt.Errorf("invalid cached session: %w", err)
+	}
+	for _, item := range turnsCmd.Val() {
+		var turn Turn
+		if err := json.Unmarshal([]byte(item), &turn); err != nil {
+			return nil, fmt.Errorf("invalid cached turn: %w", err)
+		}
+		session.Turns = append(session.Turns, turn)
+	}
+	return &session, nil
+}
+
+func (c *sessionCache) Set(ctx context.Context, session *Session) error {
+	meta, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	turns := session.Turns
+	if len(turns) > c.maxTurns {
+		turns = turns[len(turns)-c.maxTurns:]
+	}
+	encoded, err := encodeTurns(turns)
+	if err != nil {
+		return err
+	}
+
+	pipe := c.rdb.TxPipeline()
+	pipe.Set(ctx, sessionKey(session.InterviewID), meta, c.ttl)
+	pipe.Del(ctx, turnsKey(session.InterviewID))
+	if len(encoded) > 0 {
+		pipe.RPush(ctx, turnsKey(session.InterviewID), encoded...)
+		pipe.PExpire(ctx, turnsKey(session.InterviewID), c.ttl)
+	}
+	_, err = pipe.Exec(ctx)
+	return err
+}
+
+func (c *sessionCache) AppendTurns(ctx context.Context, interviewID uuid.UUID, turns ...Turn) error {
+	if len(turns) == 0 {
+		return nil
+	}
+	encoded, err := encodeTurns(turns)
+	if err != nil {
+		return err
+	}
+
+	args := append([]interface{}{c.ttl.Milliseconds(), c.maxTurns}, encoded...)
+	return appendScript.Run(ctx, c.rdb, []string{sessionKey(interviewID), turnsKey(interviewID)}, args...).Err()
+}
+
+func (c *sessionCache) SetMeta(ctx context.Context, session *Session) error {
+	meta, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+	return c.rdb.SetXX(ctx, sessionKey(session.InterviewID), meta, c.ttl).Err()
+}
+
+func (c *sessionCache) Delete(ctx context.Context, interviewID uuid.UUID) error {
+	return c.rdb.Del(ctx, sessionKey(interviewID), turnsKey(interviewID)).Err()
+}
+
+func encodeTurns(turns []Turn) ([]interface{}, error) {
+	encoded := make([]interface{}, 0, len(turns))
+	for _, turn := range turns {
+		raw, err := json.Marshal(turn)
+		if err != nil {
+			return nil, err
+		}
+		encoded = append(encoded, string(raw))
+	}
+	return encoded, nil
+}
@@ -0,0 +1,128 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"minos/internal/auth"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+type APIKeyController struct {
+	service service.APIKeyService
+}
+
+func NewAPIKeyController(service service.APIKeyService) *APIKeyController {
+	return &APIKeyController{
+		service: service,
+	}
+}
+
+func (c *APIKeyController) RegisterRoutes(router *gin.Engine, apiPrefix string) {
+	v1 := router.Group(apiPrefix)
+	{
+		// Keys are managed by admin users only, never by other keys
+		keys := v1.Group("/api-keys", auth.RequireAdmin())
+		{
+			keys.GET("", c.GetAPIKeys)
+			keys.POST("", c.IssueAPIKey)
+			keys.DELETE("/:id", c.RevokeAPIKey)
+		}
+	}
+}
+
+// GetAPIKeys godoc
+// @Summary Get all API keys
+// @Description Get every issued API key with its scopes and last use, newest first
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Success 200 {object} model.Response{data=[]model.APIKey}
+// @Failure 500 {object} model.Response
+// @Router /api-keys [get]
+func (c *APIKeyController) GetAPIKeys(ctx *gin.Context) {
+	keys, err := c.service.GetAPIKeys()
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch API keys")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch API keys", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("API keys fetched successfully", keys))
+}
+
+// IssueAPIKey godoc
+// @Summary Issue an API key
+// @Description Issue an API key for a machine client. The key is only returned in this response.
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param api_key body dto.APIKeyCreate true "Issue API key"
+// @Success 201 {object} model.Response{data=dto.APIKeyIssued}
+// @Failure 400 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api-keys [post]
+func (c *APIKeyController) IssueAPIKey(ctx *gin.Context) {
+	var input dto.APIKeyCreate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	createdBy := uuid.Nil
+	if principal := auth.FromContext(ctx); principal != nil {
+		createdBy = principal.UserID
+	}
+	issued, err := c.service.IssueAPIKey(&input, createdBy)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to issue API key")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewResponse("API key issued successfully", issued))
+}
+
+// RevokeAPIKey godoc
+// @Summary Revoke an API key
+// @Description Revoke an API key; requests using it are rejected from then on
+// @Tags api-keys
+// @Accept json
+// @Produce json
+// @Param id path string true "API key ID"
+// @Success 200 {object} model.Response{data=model.APIKey}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /api-keys/{id} [delete]
+func (c *APIKeyController) RevokeAPIKey(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	key, err := c.service.RevokeAPIKey(id)
+	if err != nil {
+		switch {
+		case errors.Is(err, gorm.ErrRecordNotFound):
+			ctx.JSON(http.StatusNotFound, model.NewResponse("API key not found", nil))
+		case errors.Is(err, service.ErrAPIKeyRevoked):
+			ctx.JSON(http.StatusConflict, model.NewResponse(err.Error(), nil))
+		default:
+			log.Error().Err(err).Str("id", id.String()).Msg("Failed to revoke API key")
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse(err.Error(), nil))
+		}
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("API key revoked successfully", key))
+}
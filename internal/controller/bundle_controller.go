@@ -0,0 +1,177 @@
+package controller
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"minos/internal/auth"
+	"minos/internal/dto"
+	"minos/internal/llm"
+	"minos/internal/model"
+	"minos/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type BundleController struct {
+	service service.BundleService
+}
+
+func NewBundleController(service service.BundleService) *BundleController {
+	return &BundleController{
+		service: service,
+	}
+}
+
+func (c *BundleController) RegisterRoutes(router *gin.Engine, apiPrefix string) {
+	v1 := router.Group(apiPrefix)
+	{
+		// Service clients additionally need the scope of every section they touch
+		bundles := v1.Group("/bundles", auth.RequireAdmin(auth.ScopePromptsAdmin, auth.ScopeProblemsAdmin))
+		{
+			bundles.GET("/export", c.ExportBundle)
+			bundles.POST("/import", c.ImportBundle)
+		}
+	}
+}
+
+// ExportBundle godoc
+// @Summary Export a bundle
+// @Description Export prompt templates (every version and release channel) and problems as a versioned YAML or JSON bundle
+// @Tags bundles
+// @Produce application/yaml
+// @Produce json
+// @Param format query string false "yaml (default) or json"
+// @Param prompt_name query string false "Only export the templates and channels of this prompt name"
+// @Param include query string false "Comma-separated sections: prompts, problems (default both)"
+// @Success 200 {object} dto.Bundle
+// @Failure 400 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /bundles/export [get]
+func (c *BundleController) ExportBundle(ctx *gin.Context) {
+	var query dto.BundleExportQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+	prompts, problems, err := service.BundleSections(query.Include)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+	if !canTouchBundleSections(ctx, prompts, problems) {
+		ctx.JSON(http.StatusForbidden, model.NewResponse("API key lacks the scope of an exported section", nil))
+		return
+	}
+
+	bundle, err := c.service.ExportBundle(&query)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to export bundle")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to export bundle", nil))
+		return
+	}
+
+	format := query.Format
+	if format == "" {
+		format = service.BundleFormatYAML
+	}
+	data, err := service.EncodeBundle(bundle, format)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to encode bundle")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to encode bundle", nil))
+		return
+	}
+
+	contentType := "application/yaml"
+	if format == service.BundleFormatJSON {
+		contentType = "application/json"
+	}
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="minos-bundle-%s.%s"`, bundle.ExportedAt.Format("20060102-150405"), format))
+	ctx.Data(http.StatusOK, contentType, data)
+}
+
+// ImportBundle godoc
+// @Summary Import a bundle
+// @Description Import a YAML or JSON bundle in one transaction. Items that differ from the stored ones are handled by the conflict policy; a dry run only reports the changes.
+// @Tags bundles
+// @Accept application/yaml
+// @Accept json
+// @Produce json
+// @Param bundle body dto.Bundle true "Bundle"
+// @Param policy query string false "skip (default), overwrite or new-version"
+// @Param dry_run query bool false "Report the changes without applying them"
+// @Param format query string false "yaml or json, defaults to the Content-Type"
+// @Success 200 {object} model.Response{data=dto.BundleImportResult}
+// @Failure 400 {object} model.Response
+// @Failure 403 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /bundles/import [post]
+func (c *BundleController) ImportBundle(ctx *gin.Context) {
+	var options dto.BundleImportOptions
+	if err := ctx.ShouldBindQuery(&options); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+	format := options.Format
+	if format == "" {
+		format = service.BundleFormatJSON
+		if strings.Contains(ctx.ContentType(), "yaml") {
+			format = service.BundleFormatYAML
+		}
+	}
+
+	data, err := ctx.GetRawData()
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+	bundle, err := service.DecodeBundle(data, format)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+	if !canTouchBundleSections(ctx, len(bundle.Prompts)+len(bundle.Channels) > 0, len(bundle.Problems) > 0) {
+		ctx.JSON(http.StatusForbidden, model.NewResponse("API key lacks the scope of an imported section", nil))
+		return
+	}
+
+	actor := "bundle-import"
+	if principal := auth.FromContext(ctx); principal != nil && principal.Subject != "" {
+		actor = principal.Subject
+	}
+	result, err := c.service.ImportBundle(bundle, &options, actor)
+	if err != nil {
+		var tmplErr *llm.TemplateError
+		switch {
+		case errors.As(err, &tmplErr):
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), tmplErr))
+		case errors.Is(err, service.ErrInvalidBundle):
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		default:
+			log.Error().Err(err).Msg("Failed to import bundle")
+			ctx.JSON(http.StatusInternalServerError, model.NewResponse(err.Error(), nil))
+		}
+		return
+	}
+
+	if result.DryRun {
+		ctx.JSON(http.StatusOK, model.NewResponse("Bundle checked, nothing was imported (dry run)", result))
+		return
+	}
+	ctx.JSON(http.StatusOK, model.NewResponse("Bundle imported successfully", result))
+}
+
+// canTouchBundleSections reports whether the caller holds the scope of every
+// section it reads or writes. Admin users may touch all of them.
+func canTouchBundleSections(ctx *gin.Context, prompts, problems bool) bool {
+	principal := auth.FromContext(ctx)
+	if principal == nil || !principal.IsService() {
+		return true
+	}
+	return (!prompts || principal.HasScope(auth.ScopePromptsAdmin)) &&
+		(!problems || principal.HasScope(auth.ScopeProblemsAdmin))
+}
@@ -5,17 +5,31 @@ import "github.com/gin-gonic/gin"
 type Controller struct {
 	PromptTemplate *PromptTemplateController
 	Interview      *InterviewController
+	Experiment     *ExperimentController
+	APIKey         *APIKeyController
+	Usage          *UsageController
+	Problem        *ProblemController
+	Bundle         *BundleController
 }
 
-func NewController(pt *PromptTemplateController, interview *InterviewController) *Controller {
+func NewController(pt *PromptTemplateController, interview *InterviewController, experiment *ExperimentController, apiKey *APIKeyController, usage *UsageController, problem *ProblemController, bundle *BundleController) *Controller {
 	return &Controller{
 		PromptTemplate: pt,
 		Interview:      interview,
+		Experiment:     experiment,
+		APIKey:         apiKey,
+		Usage:          usage,
+		Problem:        problem,
+		Bundle:         bundle,
 	}
 }
 
 func (c *Controller) RegisterRoutes(router *gin.Engine, apiPrefix string) {
 	c.PromptTemplate.RegisterRoutes(router, apiPrefix)
 	c.Interview.RegisterRoutes(router, apiPrefix)
+	c.Experiment.RegisterRoutes(router, apiPrefix)
+	c.APIKey.RegisterRoutes(router, apiPrefix)
+	c.Usage.RegisterRoutes(router, apiPrefix)
+	c.Problem.RegisterRoutes(router, apiPrefix)
+	c.Bundle.RegisterRoutes(router, apiPrefix)
 }
-
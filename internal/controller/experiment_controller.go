@@ -0,0 +1,198 @@
+package controller
+
+import (
+	"net/http"
+	"strconv"
+
+	"minos/internal/auth"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type ExperimentController struct {
+	service service.ExperimentService
+}
+
+func NewExperimentController(service service.ExperimentService) *ExperimentController {
+	return &ExperimentController{
+		service: service,
+	}
+}
+
+func (c *ExperimentController) RegisterRoutes(router *gin.Engine, apiPrefix string) {
+	v1 := router.Group(apiPrefix)
+	{
+		// Reports aggregate candidates' scores, so reads are admin-only too
+		experiments := v1.Group("/experiments", auth.RequireAdmin(auth.ScopePromptsAdmin))
+		{
+			experiments.GET("", c.GetExperiments)
+			experiments.GET("/:id", c.GetExperimentByID)
+			experiments.POST("", c.CreateExperiment)
+			experiments.POST("/:id/start", c.StartExperiment)
+			experiments.POST("/:id/stop", c.StopExperiment)
+			experiments.GET("/:id/report", c.GetExperimentReport)
+		}
+	}
+}
+
+// GetExperiments godoc
+// @Summary Get all experiments
+// @Description Get all prompt experiments, optionally for one prompt name
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param prompt_name query string false "Filter by prompt template name"
+// @Success 200 {object} model.Response{data=[]model.Experiment}
+// @Failure 500 {object} model.Response
+// @Router /experiments [get]
+func (c *ExperimentController) GetExperiments(ctx *gin.Context) {
+	experiments, err := c.service.GetExperiments(ctx.Query("prompt_name"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch experiments")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch experiments", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Experiments fetched successfully", experiments))
+}
+
+// GetExperimentByID godoc
+// @Summary Get an experiment by ID
+// @Description Get an experiment and its arms
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path int true "Experiment ID"
+// @Success 200 {object} model.Response{data=model.Experiment}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /experiments/{id} [get]
+func (c *ExperimentController) GetExperimentByID(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	experiment, err := c.service.GetExperimentByID(uint(id))
+	if err != nil {
+		log.Error().Err(err).Uint64("id", id).Msg("Failed to fetch experiment")
+		ctx.JSON(http.StatusNotFound, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Experiment fetched successfully", experiment))
+}
+
+// CreateExperiment godoc
+// @Summary Create an experiment
+// @Description Create a draft A/B experiment splitting traffic between versions of a prompt template
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param experiment body dto.ExperimentCreate true "Create experiment"
+// @Success 201 {object} model.Response{data=model.Experiment}
+// @Failure 400 {object} model.Response
+// @Router /experiments [post]
+func (c *ExperimentController) CreateExperiment(ctx *gin.Context) {
+	var input dto.ExperimentCreate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	experiment, err := c.service.CreateExperiment(&input)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to create experiment")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewResponse("Experiment created successfully", experiment))
+}
+
+// StartExperiment godoc
+// @Summary Start an experiment
+// @Description Start assigning new interviews to the arms of a draft experiment
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path int true "Experiment ID"
+// @Success 200 {object} model.Response{data=model.Experiment}
+// @Failure 400 {object} model.Response
+// @Router /experiments/{id}/start [post]
+func (c *ExperimentController) StartExperiment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	experiment, err := c.service.StartExperiment(uint(id))
+	if err != nil {
+		log.Error().Err(err).Uint64("id", id).Msg("Failed to start experiment")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Experiment started successfully", experiment))
+}
+
+// StopExperiment godoc
+// @Summary Stop an experiment
+// @Description Stop assigning new interviews; existing interviews keep their arm
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path int true "Experiment ID"
+// @Success 200 {object} model.Response{data=model.Experiment}
+// @Failure 400 {object} model.Response
+// @Router /experiments/{id}/stop [post]
+func (c *ExperimentController) StopExperiment(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	experiment, err := c.service.StopExperiment(uint(id))
+	if err != nil {
+		log.Error().Err(err).Uint64("id", id).Msg("Failed to stop experiment")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Experiment stopped successfully", experiment))
+}
+
+// GetExperimentReport godoc
+// @Summary Get an experiment report
+// @Description Aggregate evaluation scores, message counts and interview duration per arm
+// @Tags experiments
+// @Accept json
+// @Produce json
+// @Param id path int true "Experiment ID"
+// @Success 200 {object} model.Response{data=dto.ExperimentReport}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /experiments/{id}/report [get]
+func (c *ExperimentController) GetExperimentReport(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	report, err := c.service.GetExperimentReport(uint(id))
+	if err != nil {
+		log.Error().Err(err).Uint64("id", id).Msg("Failed to build experiment report")
+		ctx.JSON(http.StatusNotFound, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Experiment report fetched successfully", report))
+}
@@ -1,26 +1,48 @@
 package controller
 
 import (
+	"errors"
+	"minos/config"
+	"minos/internal/auth"
 	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/ratelimit"
 	"minos/internal/service"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
+	"gorm.io/gorm"
 )
 
 type InterviewController struct {
-	interviewService service.InterviewService
-	chatService      service.ChatService
+	interviewService  service.InterviewService
+	chatService       service.ChatService
+	submissionService service.SubmissionService
+	evaluationService service.EvaluationService
+	sessions          *sessionHub
+	limiter           ratelimit.Limiter
+	rateLimit         gin.HandlerFunc
+	cfg               *config.Config
 }
 
 func NewInterviewController(
 	interviewService service.InterviewService,
 	chatService service.ChatService,
+	submissionService service.SubmissionService,
+	evaluationService service.EvaluationService,
+	limiter ratelimit.Limiter,
+	cfg *config.Config,
 ) *InterviewController {
 	return &InterviewController{
-		interviewService: interviewService,
-		chatService:      chatService,
+		interviewService:  interviewService,
+		chatService:       chatService,
+		submissionService: submissionService,
+		evaluationService: evaluationService,
+		sessions:          newSessionHub(),
+		limiter:           limiter,
+		rateLimit:         ratelimit.Middleware(limiter, cfg),
+		cfg:               cfg,
 	}
 }
 
@@ -31,8 +53,32 @@ func (c *InterviewController) StartInterview(ctx *gin.Context) {
 		return
 	}
 
+	principal := auth.FromContext(ctx)
+	if req.UserID == uuid.Nil {
+		req.UserID = principal.UserID
+	}
+	if req.UserID == uuid.Nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "user_id is required"})
+		return
+	}
+	if !principal.CanAccessUser(req.UserID) {
+		ctx.JSON(http.StatusForbidden, gin.H{"error": "cannot start an interview for another user"})
+		return
+	}
+
 	res, err := c.interviewService.StartInterview(&req)
 	if err != nil {
+		if ratelimit.Abort(ctx, err) {
+			return
+		}
+		if errors.Is(err, service.ErrTooManyActiveInterviews) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, service.ErrProblemNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -40,6 +86,36 @@ func (c *InterviewController) StartInterview(ctx *gin.Context) {
 	ctx.JSON(http.StatusCreated, res)
 }
 
+func (c *InterviewController) ListInterviews(ctx *gin.Context) {
+	var query dto.InterviewListQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// Users other than admins only ever see their own interviews
+	principal := auth.FromContext(ctx)
+	if !principal.ActsForAnyUser() {
+		if query.UserID != "" && query.UserID != principal.UserID.String() {
+			ctx.JSON(http.StatusForbidden, gin.H{"error": "cannot list interviews of another user"})
+			return
+		}
+		query.UserID = principal.UserID.String()
+	}
+
+	res, err := c.interviewService.ListInterviews(&query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
 func (c *InterviewController) GetInterview(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -73,13 +149,88 @@ func (c *InterviewController) SendMessage(ctx *gin.Context) {
 
 	res, err := c.chatService.SendMessage(id, &req)
 	if err != nil {
-		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		chatError(ctx, err)
 		return
 	}
 
 	ctx.JSON(http.StatusOK, res)
 }
 
+// StreamMessage sends a candidate message and streams the AI reply as
+// server-sent events: "delta" events carry text chunks, followed by a single
+// "done" event with the persisted message, or an "error" event.
+func (c *InterviewController) StreamMessage(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	var req dto.SendMessageRequest
+	if ctx.Request.Method == http.MethodGet {
+		err = ctx.ShouldBindQuery(&req)
+	} else {
+		err = ctx.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	// The turn is validated before the first chunk, so the response only
+	// switches to an event stream once there is something to stream
+	streaming := false
+	openStream := func() {
+		if streaming {
+			return
+		}
+		streaming = true
+		ctx.Header("Content-Type", "text/event-stream")
+		ctx.Header("Cache-Control", "no-cache")
+		ctx.Header("Connection", "keep-alive")
+		ctx.Header("X-Accel-Buffering", "no")
+	}
+
+	res, err := c.chatService.SendMessageStream(ctx.Request.Context(), id, &req, func(delta string) error {
+		if err := ctx.Request.Context().Err(); err != nil {
+			return err
+		}
+		openStream()
+		ctx.SSEvent("delta", gin.H{"text": delta})
+		ctx.Writer.Flush()
+		return nil
+	})
+	if err != nil {
+		if !streaming {
+			chatError(ctx, err)
+			return
+		}
+		if ctx.Request.Context().Err() == nil {
+			ctx.SSEvent("error", gin.H{"error": err.Error()})
+			ctx.Writer.Flush()
+		}
+		return
+	}
+
+	openStream()
+	ctx.SSEvent("done", res)
+	ctx.Writer.Flush()
+}
+
+// chatError answers a conversation turn that failed before any reply was sent.
+func chatError(ctx *gin.Context, err error) {
+	switch {
+	case ratelimit.Abort(ctx, err):
+	case errors.Is(err, gorm.ErrRecordNotFound):
+		ctx.JSON(http.StatusNotFound, gin.H{"error": "interview not found"})
+	case errors.Is(err, service.ErrInvalidInterviewState):
+		ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+	default:
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+	}
+}
+
 func (c *InterviewController) GetHistory(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -97,6 +248,80 @@ func (c *InterviewController) GetHistory(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+// RequestHint gives the candidate the next hint, each one giving away more
+// than the last, and counts it against the evaluation.
+func (c *InterviewController) RequestHint(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	res, err := c.chatService.RequestHint(id)
+	if err != nil {
+		if errors.Is(err, service.ErrNoHintsLeft) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		chatError(ctx, err)
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *InterviewController) SubmitCode(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	var req dto.SubmitCodeRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	res, err := c.submissionService.SubmitCode(id, &req)
+	if err != nil {
+		if ratelimit.Abort(ctx, err) {
+			return
+		}
+		if errors.Is(err, service.ErrInvalidInterviewState) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "interview not found"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, res)
+}
+
+func (c *InterviewController) GetSubmissions(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	res, err := c.submissionService.GetSubmissions(id)
+	if err != nil {
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
 func (c *InterviewController) EndInterview(ctx *gin.Context) {
 	idStr := ctx.Param("id")
 	id, err := uuid.Parse(idStr)
@@ -107,6 +332,35 @@ func (c *InterviewController) EndInterview(ctx *gin.Context) {
 
 	res, err := c.interviewService.EndInterview(id)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInterviewState) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	if res.Status == string(model.EvaluationStatusSucceeded) {
+		ctx.JSON(http.StatusOK, res)
+		return
+	}
+	ctx.JSON(http.StatusAccepted, res)
+}
+
+func (c *InterviewController) PauseInterview(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	res, err := c.interviewService.PauseInterview(id)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInterviewState) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
 		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
 		return
 	}
@@ -114,16 +368,86 @@ func (c *InterviewController) EndInterview(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, res)
 }
 
+func (c *InterviewController) ResumeInterview(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	res, err := c.interviewService.ResumeInterview(id)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInterviewState) {
+			ctx.JSON(http.StatusConflict, gin.H{"error": err.Error()})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+func (c *InterviewController) GetEvaluation(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	res, err := c.evaluationService.GetEvaluation(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			ctx.JSON(http.StatusNotFound, gin.H{"error": "interview has not been ended"})
+			return
+		}
+		ctx.JSON(http.StatusInternalServerError, gin.H{"error": err.Error()})
+		return
+	}
+
+	ctx.JSON(http.StatusOK, res)
+}
+
+// interviewOwner resolves the user owning the interview in the path.
+func (c *InterviewController) interviewOwner(ctx *gin.Context) (uuid.UUID, error) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		return uuid.Nil, errors.New("interview not found")
+	}
+	owner, err := c.interviewService.GetInterviewOwner(id)
+	if err != nil {
+		return uuid.Nil, errors.New("interview not found")
+	}
+	return owner, nil
+}
+
 func (c *InterviewController) RegisterRoutes(router *gin.Engine, apiPrefix string) {
 	v1 := router.Group(apiPrefix)
 	{
-		interviews := v1.Group("/interviews")
+		interviews := v1.Group("/interviews", auth.RequireAuth(), c.rateLimit)
 		{
-			interviews.POST("", c.StartInterview)
-			interviews.GET("/:id", c.GetInterview)
-			interviews.POST("/:id/messages", c.SendMessage)
-			interviews.GET("/:id/messages", c.GetHistory)
-			interviews.POST("/:id/end", c.EndInterview)
+			interviews.POST("", auth.RequireScope(auth.ScopeInterviewsWrite), c.StartInterview)
+			interviews.GET("", auth.RequireScope(auth.ScopeInterviewsWrite, auth.ScopeEvaluationsRead), c.ListInterviews)
+
+			// Everything under an interview is limited to its owner and admins
+			interview := interviews.Group("/:id", auth.RequireOwner(c.interviewOwner))
+			interview.GET("/evaluation", auth.RequireScope(auth.ScopeEvaluationsRead, auth.ScopeInterviewsWrite), c.GetEvaluation)
+
+			conversation := interview.Group("", auth.RequireScope(auth.ScopeInterviewsWrite))
+			conversation.GET("", c.GetInterview)
+			conversation.POST("/messages", c.SendMessage)
+			conversation.GET("/messages", c.GetHistory)
+			conversation.GET("/messages/stream", c.StreamMessage)
+			conversation.POST("/messages/stream", c.StreamMessage)
+			conversation.POST("/hints", c.RequestHint)
+			conversation.POST("/submissions", c.SubmitCode)
+			conversation.GET("/submissions", c.GetSubmissions)
+			conversation.POST("/end", c.EndInterview)
+			conversation.POST("/pause", c.PauseInterview)
+			conversation.POST("/resume", c.ResumeInterview)
+			conversation.GET("/ws", c.Session)
 		}
 	}
 }
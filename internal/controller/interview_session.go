@@ -0,0 +1,288 @@
+package controller
+
+import (
+	"context"
+	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"minos/internal/auth"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/ratelimit"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/gorilla/websocket"
+	"github.com/rs/zerolog/log"
+)
+
+const (
+	sessionWriteWait  = 10 * time.Second
+	sessionPongWait   = 60 * time.Second
+	sessionPingPeriod = (sessionPongWait * 9) / 10
+)
+
+var upgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 4096,
+	// CORS is already open to all origins for the REST API
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// sessionHub keeps track of the live connections of every interview so that
+// events from one tab (or an observer) reach all the others.
+type sessionHub struct {
+	mu       sync.RWMutex
+	sessions map[uuid.UUID]map[*liveSession]struct{}
+}
+
+func newSessionHub() *sessionHub {
+	return &sessionHub{sessions: make(map[uuid.UUID]map[*liveSession]struct{})}
+}
+
+func (h *sessionHub) join(s *liveSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.sessions[s.interviewID] == nil {
+		h.sessions[s.interviewID] = make(map[*liveSession]struct{})
+	}
+	h.sessions[s.interviewID][s] = struct{}{}
+}
+
+func (h *sessionHub) leave(s *liveSession) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.sessions[s.interviewID], s)
+	if len(h.sessions[s.interviewID]) == 0 {
+		delete(h.sessions, s.interviewID)
+	}
+}
+
+// broadcast sends the event to every connection of the interview except skip.
+// Writes happen outside the lock so a slow client never holds up joins and
+// leaves.
+func (h *sessionHub) broadcast(interviewID uuid.UUID, event dto.SessionEvent, skip *liveSession) {
+	h.mu.RLock()
+	targets := make([]*liveSession, 0, len(h.sessions[interviewID]))
+	for s := range h.sessions[interviewID] {
+		if s != skip {
+			targets = append(targets, s)
+		}
+	}
+	h.mu.RUnlock()
+
+	for _, s := range targets {
+		s.send(event)
+	}
+}
+
+// liveSession is one WebSocket connection bound to an interview.
+type liveSession struct {
+	interviewID uuid.UUID
+	principal   *auth.Principal // Who opened the connection, for rate limiting
+	clientIP    string
+	conn        *websocket.Conn
+	writeMu     sync.Mutex
+	busy        atomic.Bool
+
+	snapshotMu   sync.Mutex
+	snapshotCode string
+	snapshotLang string
+	attachedCode string // Code last sent along with a message
+	attachedLang string
+}
+
+func (s *liveSession) send(event dto.SessionEvent) {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	s.conn.SetWriteDeadline(time.Now().Add(sessionWriteWait))
+	if err := s.conn.WriteJSON(event); err != nil {
+		log.Debug().Err(err).Str("interview_id", s.interviewID.String()).Msg("Failed to write session event")
+	}
+}
+
+func (s *liveSession) ping() error {
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	return s.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(sessionWriteWait))
+}
+
+func (s *liveSession) sendError(msg string) {
+	s.send(dto.SessionEvent{Type: dto.SessionEventError, Error: msg})
+}
+
+// Session upgrades the request to a WebSocket carrying the live interview:
+// candidate messages, code snapshots and typing/idle signals in, interviewer
+// reply deltas out. Pass last_message_id to resume after a reconnect.
+func (c *InterviewController) Session(ctx *gin.Context) {
+	idStr := ctx.Param("id")
+	id, err := uuid.Parse(idStr)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid interview id"})
+		return
+	}
+
+	var lastMessageID uuid.UUID
+	if raw := ctx.Query("last_message_id"); raw != "" {
+		lastMessageID, err = uuid.Parse(raw)
+		if err != nil {
+			ctx.JSON(http.StatusBadRequest, gin.H{"error": "invalid last_message_id"})
+			return
+		}
+	}
+
+	interview, err := c.interviewService.GetInterview(id)
+	if err != nil {
+		ctx.JSON(http.StatusNotFound, gin.H{"error": err.Error()})
+		return
+	}
+	if interview.Status != model.InterviewStatusActive {
+		ctx.JSON(http.StatusConflict, gin.H{"error": "interview is not active"})
+		return
+	}
+
+	conn, err := upgrader.Upgrade(ctx.Writer, ctx.Request, nil)
+	if err != nil {
+		// Upgrade already wrote the HTTP error response
+		log.Error().Err(err).Str("interview_id", id.String()).Msg("Failed to upgrade session")
+		return
+	}
+
+	session := &liveSession{interviewID: id, principal: auth.FromContext(ctx), clientIP: ctx.ClientIP(), conn: conn}
+	c.sessions.join(session)
+
+	sessionCtx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		c.sessions.leave(session)
+		conn.Close()
+	}()
+
+	// Replay what the client missed
+	var history []model.Message
+	if lastMessageID != uuid.Nil {
+		history, err = c.chatService.GetHistorySince(id, lastMessageID)
+	} else {
+		history, err = c.chatService.GetHistory(id)
+	}
+	if err != nil {
+		session.sendError("failed to load history: " + err.Error())
+		return
+	}
+	session.send(dto.SessionEvent{Type: dto.SessionEventHistory, Data: history})
+
+	go c.heartbeat(sessionCtx, session)
+
+	conn.SetReadLimit(1 << 20)
+	conn.SetReadDeadline(time.Now().Add(sessionPongWait))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(sessionPongWait))
+	})
+
+	for {
+		var event dto.SessionEvent
+		if err := conn.ReadJSON(&event); err != nil {
+			if websocket.IsUnexpectedCloseError(err, websocket.CloseGoingAway, websocket.CloseNormalClosure) {
+				log.Warn().Err(err).Str("interview_id", id.String()).Msg("Session closed unexpectedly")
+			}
+			return
+		}
+		conn.SetReadDeadline(time.Now().Add(sessionPongWait))
+
+		switch event.Type {
+		case dto.SessionEventMessage:
+			c.handleSessionMessage(sessionCtx, session, event)
+		case dto.SessionEventCodeSnapshot:
+			session.snapshotMu.Lock()
+			session.snapshotCode, session.snapshotLang = event.Code, event.Language
+			session.snapshotMu.Unlock()
+			c.sessions.broadcast(id, event, session)
+		case dto.SessionEventTyping, dto.SessionEventIdle:
+			c.sessions.broadcast(id, dto.SessionEvent{Type: event.Type}, session)
+		case dto.SessionEventPing:
+			session.send(dto.SessionEvent{Type: dto.SessionEventPong})
+		default:
+			session.sendError("unknown event type: " + event.Type)
+		}
+	}
+}
+
+func (c *InterviewController) heartbeat(ctx context.Context, session *liveSession) {
+	ticker := time.NewTicker(sessionPingPeriod)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := session.ping(); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleSessionMessage runs one chat turn in the background so the read loop
+// keeps processing heartbeats while the reply streams. Only one turn per
+// connection may be in flight, and every message counts against the same
+// rate limit as a request to the REST API.
+func (c *InterviewController) handleSessionMessage(ctx context.Context, session *liveSession, event dto.SessionEvent) {
+	if event.Content == "" {
+		session.sendError("content is required")
+		return
+	}
+	if err := ratelimit.Allow(ctx, c.limiter, c.cfg, session.principal, session.clientIP); err != nil {
+		session.sendError(err.Error())
+		return
+	}
+	if !session.busy.CompareAndSwap(false, true) {
+		session.sendError("a reply is already in progress")
+		return
+	}
+
+	req := &dto.SendMessageRequest{
+		Content:  event.Content,
+		Code:     event.Code,
+		Language: event.Language,
+	}
+	// Attach the latest editor snapshot when the message carries no code and
+	// the interviewer has not seen it yet
+	session.snapshotMu.Lock()
+	if req.Code == "" && (session.snapshotCode != session.attachedCode || session.snapshotLang != session.attachedLang) {
+		req.Code, req.Language = session.snapshotCode, session.snapshotLang
+	}
+	session.snapshotMu.Unlock()
+
+	go func() {
+		defer session.busy.Store(false)
+
+		// The other tabs see the message once the turn got through validation
+		var relay sync.Once
+		relayMessage := func() {
+			relay.Do(func() {
+				c.sessions.broadcast(session.interviewID, dto.SessionEvent{Type: dto.SessionEventMessage, Content: event.Content, Code: req.Code, Language: req.Language}, session)
+			})
+		}
+
+		res, err := c.chatService.SendMessageStream(ctx, session.interviewID, req, func(delta string) error {
+			relayMessage()
+			c.sessions.broadcast(session.interviewID, dto.SessionEvent{Type: dto.SessionEventDelta, Content: delta}, nil)
+			return nil
+		})
+		if err != nil {
+			if ctx.Err() == nil {
+				session.sendError(err.Error())
+			}
+			return
+		}
+		relayMessage()
+		if req.Code != "" {
+			session.snapshotMu.Lock()
+			session.attachedCode, session.attachedLang = req.Code, req.Language
+			session.snapshotMu.Unlock()
+		}
+		c.sessions.broadcast(session.interviewID, dto.SessionEvent{Type: dto.SessionEventReply, Data: res}, nil)
+	}()
+}
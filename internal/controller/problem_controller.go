@@ -0,0 +1,213 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"minos/internal/auth"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+type ProblemController struct {
+	service service.ProblemService
+}
+
+func NewProblemController(service service.ProblemService) *ProblemController {
+	return &ProblemController{
+		service: service,
+	}
+}
+
+func (c *ProblemController) RegisterRoutes(router *gin.Engine, apiPrefix string) {
+	v1 := router.Group(apiPrefix)
+	{
+		problems := v1.Group("/problems", auth.RequireAuth())
+		{
+			problems.GET("", auth.RequireScope(auth.ScopeInterviewsWrite, auth.ScopeProblemsAdmin), c.GetProblems)
+			problems.GET("/:id", auth.RequireScope(auth.ScopeInterviewsWrite, auth.ScopeProblemsAdmin), c.GetProblemByID)
+			problems.POST("", auth.RequireAdmin(auth.ScopeProblemsAdmin), c.CreateProblem)
+			problems.PUT("/:id", auth.RequireAdmin(auth.ScopeProblemsAdmin), c.UpdateProblem)
+			problems.DELETE("/:id", auth.RequireAdmin(auth.ScopeProblemsAdmin), c.DeleteProblem)
+		}
+	}
+}
+
+// GetProblems godoc
+// @Summary Get all problems
+// @Description Get the problems of the problem bank with optional filters. Hidden test cases, reference solutions and editorials are only included for admins.
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Param difficulty query string false "Filter by difficulty (easy, medium or hard)"
+// @Param tag query string false "Filter by tag"
+// @Param q query string false "Search the title and slug"
+// @Success 200 {object} model.Response{data=[]model.Problem}
+// @Failure 400 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /problems [get]
+func (c *ProblemController) GetProblems(ctx *gin.Context) {
+	var query dto.ProblemQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid query parameters", nil))
+		return
+	}
+
+	problems, err := c.service.GetProblems(&query)
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch problems")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch problems", nil))
+		return
+	}
+
+	if !canSeeSolutions(ctx) {
+		for i := range problems {
+			problems[i] = problems[i].Public()
+		}
+	}
+	ctx.JSON(http.StatusOK, model.NewResponse("Problems fetched successfully", problems))
+}
+
+// GetProblemByID godoc
+// @Summary Get a problem by ID
+// @Description Get a problem of the problem bank. Hidden test cases, reference solution and editorial are only included for admins.
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Param id path string true "Problem ID"
+// @Success 200 {object} model.Response{data=model.Problem}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Router /problems/{id} [get]
+func (c *ProblemController) GetProblemByID(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	problem, err := c.service.GetProblemByID(id)
+	if err != nil {
+		c.fail(ctx, err, id, "Failed to fetch problem")
+		return
+	}
+
+	if !canSeeSolutions(ctx) {
+		*problem = problem.Public()
+	}
+	ctx.JSON(http.StatusOK, model.NewResponse("Problem fetched successfully", problem))
+}
+
+// CreateProblem godoc
+// @Summary Create a problem
+// @Description Add a problem to the problem bank
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Param problem body dto.ProblemCreate true "Create problem"
+// @Success 201 {object} model.Response{data=model.Problem}
+// @Failure 400 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /problems [post]
+func (c *ProblemController) CreateProblem(ctx *gin.Context) {
+	var input dto.ProblemCreate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	problem, err := c.service.CreateProblem(&input)
+	if err != nil {
+		c.fail(ctx, err, uuid.Nil, "Failed to create problem")
+		return
+	}
+
+	ctx.JSON(http.StatusCreated, model.NewResponse("Problem created successfully", problem))
+}
+
+// UpdateProblem godoc
+// @Summary Update a problem
+// @Description Update a problem of the problem bank. Interviews already started keep their snapshot of it.
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Param id path string true "Problem ID"
+// @Param problem body dto.ProblemUpdate true "Update problem"
+// @Success 200 {object} model.Response{data=model.Problem}
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 409 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /problems/{id} [put]
+func (c *ProblemController) UpdateProblem(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	var input dto.ProblemUpdate
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	problem, err := c.service.UpdateProblem(id, &input)
+	if err != nil {
+		c.fail(ctx, err, id, "Failed to update problem")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Problem updated successfully", problem))
+}
+
+// DeleteProblem godoc
+// @Summary Delete a problem
+// @Description Delete a problem by ID (soft delete). Interviews already started keep their snapshot of it.
+// @Tags problems
+// @Accept json
+// @Produce json
+// @Param id path string true "Problem ID"
+// @Success 200 {object} model.Response
+// @Failure 400 {object} model.Response
+// @Failure 404 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /problems/{id} [delete]
+func (c *ProblemController) DeleteProblem(ctx *gin.Context) {
+	id, err := uuid.Parse(ctx.Param("id"))
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	if err := c.service.DeleteProblem(id); err != nil {
+		c.fail(ctx, err, id, "Failed to delete problem")
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Problem deleted successfully", nil))
+}
+
+func (c *ProblemController) fail(ctx *gin.Context, err error, id uuid.UUID, msg string) {
+	switch {
+	case errors.Is(err, service.ErrProblemNotFound):
+		ctx.JSON(http.StatusNotFound, model.NewResponse(err.Error(), nil))
+	case errors.Is(err, service.ErrProblemSlugTaken):
+		ctx.JSON(http.StatusConflict, model.NewResponse(err.Error(), nil))
+	default:
+		log.Error().Err(err).Str("id", id.String()).Msg(msg)
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse(err.Error(), nil))
+	}
+}
+
+// canSeeSolutions reports whether the caller may see what candidates must not.
+func canSeeSolutions(ctx *gin.Context) bool {
+	principal := auth.FromContext(ctx)
+	return principal != nil && (principal.IsAdmin() || principal.HasScope(auth.ScopeProblemsAdmin))
+}
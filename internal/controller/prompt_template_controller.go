@@ -1,10 +1,13 @@
 package controller
 
 import (
+	"errors"
 	"net/http"
 	"strconv"
 
+	"minos/internal/auth"
 	"minos/internal/dto"
+	"minos/internal/llm"
 	"minos/internal/model"
 	"minos/internal/service"
 
@@ -32,9 +35,16 @@ func (c *PromptTemplateController) RegisterRoutes(router *gin.Engine, apiPrefix
 			prompts.GET("", c.GetAllPromptTemplates)
 			prompts.GET("/:id", c.GetPromptTemplateByID)
 			prompts.GET("/by-name-version", c.GetPromptTemplateByNameVersion)
-			prompts.POST("", c.CreatePromptTemplate)
-			prompts.PUT("/:id", c.UpdatePromptTemplate)
-			prompts.DELETE("/:id", c.DeletePromptTemplate)
+			prompts.POST("", auth.RequireAdmin(auth.ScopePromptsAdmin), c.CreatePromptTemplate)
+			prompts.PUT("/:id", auth.RequireAdmin(auth.ScopePromptsAdmin), c.UpdatePromptTemplate)
+			prompts.DELETE("/:id", auth.RequireAdmin(auth.ScopePromptsAdmin), c.DeletePromptTemplate)
+			prompts.POST("/:id/render", c.RenderPromptTemplate)
+
+			// Release channel routes
+			prompts.GET("/channels", c.GetPromptChannels)
+			prompts.GET("/channels/:name/:channel/history", c.GetPromptChannelHistory)
+			prompts.POST("/channels/:name/:channel/promote", auth.RequireAdmin(auth.ScopePromptsAdmin), c.PromotePromptTemplate)
+			prompts.POST("/channels/:name/:channel/rollback", auth.RequireAdmin(auth.ScopePromptsAdmin), c.RollbackPromptChannel)
 		}
 	}
 }
@@ -161,6 +171,11 @@ func (c *PromptTemplateController) CreatePromptTemplate(ctx *gin.Context) {
 
 	template, err := c.service.CreatePromptTemplate(&input)
 	if err != nil {
+		var tmplErr *llm.TemplateError
+		if errors.As(err, &tmplErr) {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), tmplErr))
+			return
+		}
 		log.Error().Err(err).Msg("Failed to create prompt template")
 		ctx.JSON(http.StatusInternalServerError, model.NewResponse(err.Error(), nil))
 		return
@@ -197,6 +212,11 @@ func (c *PromptTemplateController) UpdatePromptTemplate(ctx *gin.Context) {
 
 	template, err := c.service.UpdatePromptTemplate(uint(id), &input)
 	if err != nil {
+		var tmplErr *llm.TemplateError
+		if errors.As(err, &tmplErr) {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), tmplErr))
+			return
+		}
 		log.Error().Err(err).Uint64("id", id).Msg("Failed to update prompt template")
 		ctx.JSON(http.StatusInternalServerError, model.NewResponse(err.Error(), nil))
 		return
@@ -232,3 +252,155 @@ func (c *PromptTemplateController) DeletePromptTemplate(ctx *gin.Context) {
 
 	ctx.JSON(http.StatusOK, model.NewResponse("Prompt template deleted successfully", nil))
 }
+
+// RenderPromptTemplate godoc
+// @Summary Render a prompt template
+// @Description Render a prompt template with the supplied variable values. Missing, unexpected or mistyped variables are reported individually.
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param id path int true "Prompt Template ID"
+// @Param values body dto.PromptTemplateRender true "Variable values"
+// @Success 200 {object} model.Response{data=dto.PromptTemplateRendered}
+// @Failure 400 {object} model.Response{data=llm.TemplateError}
+// @Failure 404 {object} model.Response
+// @Router /prompts/{id}/render [post]
+func (c *PromptTemplateController) RenderPromptTemplate(ctx *gin.Context) {
+	id, err := strconv.ParseUint(ctx.Param("id"), 10, 32)
+	if err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse("Invalid ID format", nil))
+		return
+	}
+
+	var input dto.PromptTemplateRender
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	rendered, err := c.service.RenderPromptTemplate(uint(id), input.Values)
+	if err != nil {
+		var tmplErr *llm.TemplateError
+		if errors.As(err, &tmplErr) {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), tmplErr))
+			return
+		}
+		log.Error().Err(err).Uint64("id", id).Msg("Failed to render prompt template")
+		ctx.JSON(http.StatusNotFound, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Prompt template rendered successfully", dto.PromptTemplateRendered{Rendered: rendered}))
+}
+
+// GetPromptChannels godoc
+// @Summary List prompt release channels
+// @Description List the release channels of every prompt template name, or of one name
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param name query string false "Filter by template name"
+// @Success 200 {object} model.Response{data=[]model.PromptChannel}
+// @Failure 500 {object} model.Response
+// @Router /prompts/channels [get]
+func (c *PromptTemplateController) GetPromptChannels(ctx *gin.Context) {
+	channels, err := c.service.GetPromptChannels(ctx.Query("name"))
+	if err != nil {
+		log.Error().Err(err).Msg("Failed to fetch prompt channels")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to fetch prompt channels", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Prompt channels fetched successfully", channels))
+}
+
+// GetPromptChannelHistory godoc
+// @Summary Get the release history of a prompt channel
+// @Description List promotions and rollbacks of a channel, newest first
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param channel path string true "Release channel (production, staging, canary)"
+// @Success 200 {object} model.Response{data=[]model.PromptChannelHistory}
+// @Failure 400 {object} model.Response
+// @Router /prompts/channels/{name}/{channel}/history [get]
+func (c *PromptTemplateController) GetPromptChannelHistory(ctx *gin.Context) {
+	name := ctx.Param("name")
+	channel := ctx.Param("channel")
+
+	history, err := c.service.GetPromptChannelHistory(name, channel)
+	if err != nil {
+		log.Error().Err(err).Str("name", name).Str("channel", channel).Msg("Failed to fetch prompt channel history")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Prompt channel history fetched successfully", history))
+}
+
+// PromotePromptTemplate godoc
+// @Summary Promote a prompt template version to a release channel
+// @Description Atomically point a release channel at the given version and record it in the history
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param channel path string true "Release channel (production, staging, canary)"
+// @Param promotion body dto.PromptChannelPromote true "Version to promote"
+// @Success 200 {object} model.Response{data=model.PromptChannel}
+// @Failure 400 {object} model.Response
+// @Router /prompts/channels/{name}/{channel}/promote [post]
+func (c *PromptTemplateController) PromotePromptTemplate(ctx *gin.Context) {
+	name := ctx.Param("name")
+	channel := ctx.Param("channel")
+
+	var input dto.PromptChannelPromote
+	if err := ctx.ShouldBindJSON(&input); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	pc, err := c.service.PromotePromptTemplate(name, channel, &input, performedBy(ctx))
+	if err != nil {
+		log.Error().Err(err).Str("name", name).Str("channel", channel).Str("version", input.Version).Msg("Failed to promote prompt template")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Prompt template promoted successfully", pc))
+}
+
+// RollbackPromptChannel godoc
+// @Summary Roll back a prompt release channel
+// @Description Atomically point a release channel back at the version it had before its current promotion
+// @Tags prompts
+// @Accept json
+// @Produce json
+// @Param name path string true "Template name"
+// @Param channel path string true "Release channel (production, staging, canary)"
+// @Success 200 {object} model.Response{data=model.PromptChannel}
+// @Failure 400 {object} model.Response
+// @Router /prompts/channels/{name}/{channel}/rollback [post]
+func (c *PromptTemplateController) RollbackPromptChannel(ctx *gin.Context) {
+	name := ctx.Param("name")
+	channel := ctx.Param("channel")
+
+	pc, err := c.service.RollbackPromptChannel(name, channel, performedBy(ctx))
+	if err != nil {
+		log.Error().Err(err).Str("name", name).Str("channel", channel).Msg("Failed to roll back prompt channel")
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Prompt channel rolled back successfully", pc))
+}
+
+// performedBy is who the release history records for a channel change: the
+// authenticated caller, never a name taken from the request.
+func performedBy(ctx *gin.Context) string {
+	if principal := auth.FromContext(ctx); principal != nil && principal.Subject != "" {
+		return principal.Subject
+	}
+	return "unknown"
+}
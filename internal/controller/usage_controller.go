@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"minos/internal/auth"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog/log"
+)
+
+type UsageController struct {
+	service service.UsageService
+}
+
+func NewUsageController(service service.UsageService) *UsageController {
+	return &UsageController{
+		service: service,
+	}
+}
+
+func (c *UsageController) RegisterRoutes(router *gin.Engine, apiPrefix string) {
+	v1 := router.Group(apiPrefix)
+	{
+		v1.GET("/usage", auth.RequireAdmin(), c.GetUsageReport)
+	}
+}
+
+// GetUsageReport godoc
+// @Summary Get LLM usage
+// @Description Get token counts and cost of every LLM call (greetings, chat turns, reviews and evaluations) by UTC day and model
+// @Tags usage
+// @Accept json
+// @Produce json
+// @Param from query string false "First day (YYYY-MM-DD), 30 days before to by default"
+// @Param to query string false "Last day, inclusive (YYYY-MM-DD), today by default"
+// @Param user_id query string false "Only count the interviews of this user"
+// @Success 200 {object} model.Response{data=dto.UsageReport}
+// @Failure 400 {object} model.Response
+// @Failure 500 {object} model.Response
+// @Router /usage [get]
+func (c *UsageController) GetUsageReport(ctx *gin.Context) {
+	var query dto.UsageQuery
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+		return
+	}
+
+	report, err := c.service.GetUsageReport(&query)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			ctx.JSON(http.StatusBadRequest, model.NewResponse(err.Error(), nil))
+			return
+		}
+		log.Error().Err(err).Msg("Failed to build usage report")
+		ctx.JSON(http.StatusInternalServerError, model.NewResponse("Failed to build usage report", nil))
+		return
+	}
+
+	ctx.JSON(http.StatusOK, model.NewResponse("Usage fetched successfully", report))
+}
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"minos/internal/model"
+	"time"
+)
+
+// APIKeyCreate represents the data structure for issuing an API key
+// @Description API key creation request body
+type APIKeyCreate struct {
+	// Name of the client using the key
+	Name string `json:"name" example:"backend" binding:"required"`
+
+	// Scopes granted to the key
+	Scopes []string `json:"scopes" example:"interviews:write" binding:"required,min=1,dive,oneof=interviews:write prompts:admin evaluations:read problems:admin"`
+
+	// Optional expiry, the key never expires when omitted
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+}
+
+// APIKeyIssued is returned once when a key is issued; the plaintext key
+// cannot be retrieved again.
+// @Description Newly issued API key
+type APIKeyIssued struct {
+	Key    string        `json:"key" example:"mnk_3q2x..."`
+	APIKey *model.APIKey `json:"api_key"`
+}
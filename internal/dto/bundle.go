@@ -0,0 +1,115 @@
+package dto
+
+import (
+	"minos/internal/model"
+	"time"
+)
+
+// BundleFormatVersion is the version of the bundle format written by exports.
+// Imports reject bundles of any other version.
+const BundleFormatVersion = 1
+
+// Bundle conflict policies, applied when an imported item differs from the
+// stored one with the same key.
+const (
+	BundlePolicySkip       = "skip"        // Keep the stored item
+	BundlePolicyOverwrite  = "overwrite"   // Replace the stored item
+	BundlePolicyNewVersion = "new-version" // Import the item under a new version or slug
+)
+
+// Bundle actions reported for every imported item.
+const (
+	BundleActionCreate     = "create"
+	BundleActionUpdate     = "update"
+	BundleActionNewVersion = "new-version"
+	BundleActionSkip       = "skip"
+	BundleActionUnchanged  = "unchanged"
+)
+
+// Bundle is a portable set of prompt templates, release channels and
+// problems, serialized as YAML or JSON.
+// @Description Prompt templates, release channels and problems to move between environments
+type Bundle struct {
+	FormatVersion int             `json:"format_version" yaml:"format_version"`
+	ExportedAt    *time.Time      `json:"exported_at,omitempty" yaml:"exported_at,omitempty"`
+	Prompts       []BundlePrompt  `json:"prompts,omitempty" yaml:"prompts,omitempty"`   // Every version of each template
+	Channels      []BundleChannel `json:"channels,omitempty" yaml:"channels,omitempty"` // Applied after the prompts
+	Problems      []BundleProblem `json:"problems,omitempty" yaml:"problems,omitempty"`
+}
+
+// BundlePrompt is one version of a prompt template, keyed by name and version.
+type BundlePrompt struct {
+	Name        string `json:"name" yaml:"name"`
+	Version     string `json:"version" yaml:"version"`
+	Description string `json:"description,omitempty" yaml:"description,omitempty"`
+	Content     string `json:"content" yaml:"content"`
+	Variables   string `json:"variables,omitempty" yaml:"variables,omitempty"`
+	IsActive    *bool  `json:"is_active,omitempty" yaml:"is_active,omitempty"` // Defaults to true
+}
+
+// BundleChannel points a release channel of a template name at a version.
+type BundleChannel struct {
+	Name    string `json:"name" yaml:"name"`
+	Channel string `json:"channel" yaml:"channel"`
+	Version string `json:"version" yaml:"version"`
+}
+
+// BundleProblem is a problem of the problem bank, keyed by slug.
+type BundleProblem struct {
+	Slug              string                  `json:"slug" yaml:"slug"`
+	Title             string                  `json:"title" yaml:"title"`
+	Statement         string                  `json:"statement" yaml:"statement"`
+	Difficulty        model.ProblemDifficulty `json:"difficulty" yaml:"difficulty"`
+	Tags              []string                `json:"tags,omitempty" yaml:"tags,omitempty"`
+	Constraints       []string                `json:"constraints,omitempty" yaml:"constraints,omitempty"`
+	Examples          []model.ProblemExample  `json:"examples,omitempty" yaml:"examples,omitempty"`
+	HiddenTestCases   []model.ProblemTestCase `json:"hidden_test_cases,omitempty" yaml:"hidden_test_cases,omitempty"`
+	ReferenceSolution string                  `json:"reference_solution,omitempty" yaml:"reference_solution,omitempty"`
+	ReferenceLanguage string                  `json:"reference_language,omitempty" yaml:"reference_language,omitempty"`
+	Editorial         string                  `json:"editorial,omitempty" yaml:"editorial,omitempty"`
+}
+
+// BundleExportQuery represents query parameters for exporting a bundle
+// @Description Query parameters for exporting a bundle
+type BundleExportQuery struct {
+	// yaml (default) or json
+	Format string `form:"format" example:"yaml" binding:"omitempty,oneof=yaml json"`
+
+	// Only export the templates and channels of this prompt name
+	PromptName string `form:"prompt_name" example:"interviewer"`
+
+	// Comma-separated sections to export: prompts, problems. Defaults to both
+	Include string `form:"include" example:"prompts,problems"`
+}
+
+// BundleImportOptions represents query parameters for importing a bundle
+// @Description Query parameters for importing a bundle
+type BundleImportOptions struct {
+	// What to do with items that differ from the stored ones: skip (default), overwrite or new-version
+	Policy string `form:"policy" example:"skip" binding:"omitempty,oneof=skip overwrite new-version"`
+
+	// Report the changes without applying them
+	DryRun bool `form:"dry_run" example:"true"`
+
+	// yaml or json; defaults to the Content-Type of the request
+	Format string `form:"format" example:"yaml" binding:"omitempty,oneof=yaml json"`
+}
+
+// BundleChange is what an import does, or would do, with one item.
+type BundleChange struct {
+	Kind   string   `json:"kind" example:"prompt"`               // prompt, channel or problem
+	Key    string   `json:"key" example:"interviewer@v1.2.0"`    // name@version, name/channel or slug
+	Action string   `json:"action" example:"update"`             // create, update, new-version, skip or unchanged
+	Target string   `json:"target,omitempty" example:"v1.2.0-2"` // Version or slug created by new-version, version a channel moves to
+	From   string   `json:"from,omitempty" example:"v1.1.0"`     // Version a channel currently points at
+	Fields []string `json:"fields,omitempty" example:"content"`  // Fields that differ from the stored item
+}
+
+// BundleImportResult reports the changes of an import.
+// @Description Result of a bundle import
+type BundleImportResult struct {
+	DryRun  bool           `json:"dry_run"`
+	Policy  string         `json:"policy"`
+	Changes []BundleChange `json:"changes"`
+	Summary map[string]int `json:"summary"` // Number of changes per action
+}
@@ -0,0 +1,36 @@
+package dto
+
+import "minos/internal/model"
+
+// ExperimentArmCreate represents one arm of a new experiment
+// @Description Experiment arm creation request body
+type ExperimentArmCreate struct {
+	// Name of the arm
+	Name string `json:"name" example:"control" binding:"required"`
+
+	// Version of the prompt template served by this arm
+	Version string `json:"version" example:"v1.0.0" binding:"required"`
+
+	// Relative share of traffic
+	Weight int `json:"weight" example:"50" binding:"required,min=1"`
+}
+
+// ExperimentCreate represents the data structure for creating an experiment
+// @Description Experiment creation request body
+type ExperimentCreate struct {
+	// Unique name of the experiment
+	Name string `json:"name" example:"interviewer-tone-2024" binding:"required"`
+
+	// Prompt template name under test
+	PromptName string `json:"prompt_name" example:"interviewer" binding:"required"`
+
+	// Arms to split traffic between (at least two)
+	Arms []ExperimentArmCreate `json:"arms" binding:"required,min=2,dive"`
+}
+
+// ExperimentReport represents the aggregated outcome of an experiment
+// @Description Experiment report
+type ExperimentReport struct {
+	Experiment *model.Experiment          `json:"experiment"`
+	Arms       []model.ExperimentArmStats `json:"arms"`
+}
@@ -1,14 +1,17 @@
 package dto
 
 import (
+	"minos/internal/model"
+
 	"github.com/google/uuid"
 	"gorm.io/datatypes"
 )
 
 type StartInterviewRequest struct {
-	UserID          uuid.UUID      `json:"user_id" binding:"required"`
+	UserID          uuid.UUID      `json:"user_id"` // Defaults to the authenticated user, only admins may set another
 	ProblemID       uuid.UUID      `json:"problem_id" binding:"required"`
-	ProblemSnapshot datatypes.JSON `json:"problem_snapshot" binding:"required"`
+	ProblemSnapshot datatypes.JSON `json:"problem_snapshot,omitempty"`                           // Captured from the problem bank when omitted
+	DurationMinutes *int           `json:"duration_minutes,omitempty" binding:"omitempty,min=0"` // Overrides the configured default, 0 for untimed
 }
 
 type StartInterviewResponse struct {
@@ -16,10 +19,19 @@ type StartInterviewResponse struct {
 	Greeting    string    `json:"greeting"`
 }
 
+// InterviewTimeResponse reports the status and time budget of an interview.
+type InterviewTimeResponse struct {
+	InterviewID      uuid.UUID `json:"interview_id"`
+	Status           string    `json:"status"`
+	DurationMinutes  int       `json:"duration_minutes"`
+	ActiveSeconds    int64     `json:"active_seconds"`
+	RemainingSeconds *int64    `json:"remaining_seconds,omitempty"` // Omitted for untimed interviews
+}
+
 type SendMessageRequest struct {
-	Content  string `json:"content" binding:"required"`
-	Code     string `json:"code,omitempty"`     // Optional attached code
-	Language string `json:"language,omitempty"` // Optional language (e.g., "python", "go")
+	Content  string `json:"content" form:"content" binding:"required"`
+	Code     string `json:"code,omitempty" form:"code"`         // Optional attached code
+	Language string `json:"language,omitempty" form:"language"` // Optional language (e.g., "python", "go")
 }
 
 type SendMessageResponse struct {
@@ -27,8 +39,48 @@ type SendMessageResponse struct {
 	AIResponse string    `json:"ai_response"`
 }
 
+// HintResponse is the next hint given to the candidate.
+type HintResponse struct {
+	MessageID uuid.UUID `json:"message_id"`
+	Level     int       `json:"level"`      // 1 for the first hint, each one gives away more
+	LevelName string    `json:"level_name"` // nudge, approach or pseudo-code
+	Hint      string    `json:"hint"`
+	HintsLeft int       `json:"hints_left"`
+}
+
+type SubmitCodeRequest struct {
+	Code     string `json:"code" binding:"required"`
+	Language string `json:"language" binding:"required"` // e.g., "python", "go"
+}
+
 type EndInterviewResponse struct {
-	EvaluationID uuid.UUID `json:"evaluation_id"`
-	OverallScore int       `json:"overall_score"`
-	Feedback     string    `json:"feedback"`
+	JobID        uuid.UUID `json:"job_id"` // ID of the evaluation being produced
+	Status       string    `json:"status"` // pending, running, succeeded or failed
+	OverallScore int       `json:"overall_score,omitempty"`
+	Feedback     string    `json:"feedback,omitempty"`
+	Error        string    `json:"error,omitempty"`
+}
+
+// InterviewListQuery represents query parameters for listing interviews
+type InterviewListQuery struct {
+	UserID    string `form:"user_id"`
+	Status    string `form:"status"` // One status or a comma-separated list
+	ProblemID string `form:"problem_id"`
+	From      string `form:"from"`                                                    // RFC 3339, inclusive lower bound on started_at
+	To        string `form:"to"`                                                      // RFC 3339, exclusive upper bound on started_at
+	Sort      string `form:"sort" binding:"omitempty,oneof=started_at overall_score"` // Defaults to started_at
+	Order     string `form:"order" binding:"omitempty,oneof=asc desc"`                // Defaults to desc
+	Cursor    string `form:"cursor"`                                                  // next_cursor of the previous page
+	Limit     int    `form:"limit" binding:"omitempty,min=1,max=100"`                 // Defaults to 20
+}
+
+type InterviewListResponse struct {
+	Items      []model.InterviewSummary `json:"items"`
+	NextCursor string                   `json:"next_cursor,omitempty"` // Empty on the last page
+}
+
+// InterviewDetailResponse is an interview with what its LLM calls cost so far.
+type InterviewDetailResponse struct {
+	*model.Interview
+	Usage *model.UsageTotals `json:"usage"`
 }
@@ -0,0 +1,59 @@
+package dto
+
+import "minos/internal/model"
+
+// ProblemCreate represents the data structure for adding a problem to the problem bank
+// @Description Problem creation request body
+type ProblemCreate struct {
+	// URL-friendly identifier, unique in the problem bank
+	Slug string `json:"slug" example:"two-sum" binding:"required,max=100"`
+
+	// Title shown to the candidate
+	Title string `json:"title" example:"Two Sum" binding:"required"`
+
+	// Problem statement shown to the candidate
+	Statement string `json:"statement" example:"Given an array of integers..." binding:"required"`
+
+	// One of easy, medium or hard
+	Difficulty model.ProblemDifficulty `json:"difficulty" example:"easy" binding:"required,oneof=easy medium hard"`
+
+	Tags        []string               `json:"tags" example:"arrays,hash-table"`
+	Constraints []string               `json:"constraints" example:"2 <= nums.length <= 10^4"`
+	Examples    []model.ProblemExample `json:"examples"`
+
+	// Test cases submissions are run against, never shown to the candidate
+	HiddenTestCases []model.ProblemTestCase `json:"hidden_test_cases"`
+
+	ReferenceSolution string `json:"reference_solution"`
+	ReferenceLanguage string `json:"reference_language" example:"python"`
+	Editorial         string `json:"editorial"`
+}
+
+// ProblemUpdate represents the data structure for updating a problem; omitted fields are kept
+// @Description Problem update request body
+type ProblemUpdate struct {
+	Slug              *string                  `json:"slug,omitempty" binding:"omitempty,min=1,max=100"`
+	Title             *string                  `json:"title,omitempty" binding:"omitempty,min=1"`
+	Statement         *string                  `json:"statement,omitempty" binding:"omitempty,min=1"`
+	Difficulty        *model.ProblemDifficulty `json:"difficulty,omitempty" binding:"omitempty,oneof=easy medium hard"`
+	Tags              *[]string                `json:"tags,omitempty"`
+	Constraints       *[]string                `json:"constraints,omitempty"`
+	Examples          *[]model.ProblemExample  `json:"examples,omitempty"`
+	HiddenTestCases   *[]model.ProblemTestCase `json:"hidden_test_cases,omitempty"`
+	ReferenceSolution *string                  `json:"reference_solution,omitempty"`
+	ReferenceLanguage *string                  `json:"reference_language,omitempty"`
+	Editorial         *string                  `json:"editorial,omitempty"`
+}
+
+// ProblemQuery represents query parameters for listing problems
+// @Description Query parameters for filtering problems
+type ProblemQuery struct {
+	// Filter by difficulty
+	Difficulty string `form:"difficulty" example:"medium" binding:"omitempty,oneof=easy medium hard"`
+
+	// Filter by tag
+	Tag string `form:"tag" example:"graphs"`
+
+	// Case-insensitive search on the title and slug
+	Search string `form:"q" example:"sum"`
+}
@@ -15,7 +15,8 @@ type PromptTemplateCreate struct {
 	// The actual prompt content/template
 	Content string `json:"content" example:"You are an expert code reviewer..." binding:"required"`
 
-	// JSON string defining expected variables (optional)
+	// JSON object declaring the variables the content may reference, either as a bare
+	// type name (required) or as {"type", "required", "description", "default"} (optional)
 	Variables string `json:"variables" example:"{\"language\": \"string\", \"code\": \"string\"}"`
 
 	// Whether this template is active and can be used
@@ -50,3 +51,24 @@ type PromptTemplateQuery struct {
 	// Filter by active status
 	IsActive *bool `form:"is_active" example:"true"`
 }
+
+// PromptTemplateRender represents the values used to render a prompt template
+// @Description Prompt template render request body
+type PromptTemplateRender struct {
+	// Values for the variables declared by the template
+	Values map[string]interface{} `json:"values" swaggertype:"object"`
+}
+
+// PromptTemplateRendered represents a rendered prompt template
+// @Description Prompt template render result
+type PromptTemplateRendered struct {
+	// The rendered prompt
+	Rendered string `json:"rendered" example:"You are an expert go code reviewer..."`
+}
+
+// PromptChannelPromote represents the data structure for promoting a version to a release channel
+// @Description Prompt channel promotion request body
+type PromptChannelPromote struct {
+	// Version of the template to point the channel at
+	Version string `json:"version" example:"v1.1.0" binding:"required"`
+}
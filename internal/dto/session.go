@@ -0,0 +1,28 @@
+package dto
+
+// Session event types exchanged over the interview WebSocket.
+const (
+	// Client -> server
+	SessionEventMessage      = "message"       // Candidate chat message, relayed to the other connections
+	SessionEventCodeSnapshot = "code_snapshot" // Latest code editor content
+	SessionEventTyping       = "typing"        // Candidate started typing
+	SessionEventIdle         = "idle"          // Candidate went idle
+	SessionEventPing         = "ping"          // Application-level heartbeat
+
+	// Server -> client
+	SessionEventHistory = "history" // Messages missed since last_message_id
+	SessionEventDelta   = "delta"   // Chunk of the interviewer reply
+	SessionEventReply   = "reply"   // Interviewer reply completed and persisted
+	SessionEventPong    = "pong"
+	SessionEventError   = "error"
+)
+
+// SessionEvent is a single JSON frame on the interview WebSocket.
+type SessionEvent struct {
+	Type     string      `json:"type"`
+	Content  string      `json:"content,omitempty"`
+	Code     string      `json:"code,omitempty"`
+	Language string      `json:"language,omitempty"`
+	Data     interface{} `json:"data,omitempty"`
+	Error    string      `json:"error,omitempty"`
+}
@@ -0,0 +1,25 @@
+package dto
+
+import "minos/internal/model"
+
+// UsageQuery selects the period of a usage report
+// @Description Usage report query parameters
+type UsageQuery struct {
+	// First day of the report (YYYY-MM-DD, UTC), 30 days ago by default
+	From string `form:"from" example:"2026-01-01" binding:"omitempty,datetime=2006-01-02"`
+
+	// Last day of the report, inclusive, today by default
+	To string `form:"to" example:"2026-01-31" binding:"omitempty,datetime=2006-01-02"`
+
+	// Only count the interviews of this user
+	UserID string `form:"user_id" binding:"omitempty,uuid"`
+}
+
+// UsageReport is the LLM usage of a period by day and model
+// @Description Usage report
+type UsageReport struct {
+	From  string             `json:"from"`
+	To    string             `json:"to"`
+	Days  []model.DailyUsage `json:"days"`
+	Total model.UsageTotals  `json:"total"`
+}
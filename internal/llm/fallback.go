@@ -0,0 +1,119 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// FallbackProvider tries each provider in order until one of them answers.
+// A provider is skipped when it errors, times out or returns an empty response.
+type FallbackProvider struct {
+	providers []Provider
+	timeout   time.Duration
+}
+
+func NewFallbackProvider(timeout time.Duration, providers ...Provider) *FallbackProvider {
+	return &FallbackProvider{
+		providers: providers,
+		timeout:   timeout,
+	}
+}
+
+func (f *FallbackProvider) Generate(ctx context.Context, prompt string) (*Response, error) {
+	return f.try(ctx, func(ctx context.Context, p Provider) (*Response, error) {
+		return p.Generate(ctx, prompt)
+	})
+}
+
+func (f *FallbackProvider) Chat(ctx context.Context, system string, history []Message, message string) (*Response, error) {
+	return f.try(ctx, func(ctx context.Context, p Provider) (*Response, error) {
+		return p.Chat(ctx, system, history, message)
+	})
+}
+
+// ChatStream only falls back while nothing has been streamed yet; once the
+// caller has seen output from a model, switching models would garble the reply.
+// The timeout applies to the first chunk rather than the whole stream.
+func (f *FallbackProvider) ChatStream(ctx context.Context, system string, history []Message, message string, onDelta DeltaFunc) (*Response, error) {
+	if len(f.providers) == 0 {
+		return nil, errors.New("no LLM provider configured")
+	}
+
+	var errs []error
+	for i, p := range f.providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		attemptCtx, cancel := context.WithCancel(ctx)
+		var timer *time.Timer
+		if f.timeout > 0 {
+			timer = time.AfterFunc(f.timeout, cancel)
+		}
+		streamed := false
+		resp, err := p.ChatStream(attemptCtx, system, history, message, func(delta string) error {
+			if !streamed {
+				streamed = true
+				if timer != nil {
+					timer.Stop()
+				}
+			}
+			return onDelta(delta)
+		})
+		if timer != nil {
+			timer.Stop()
+		}
+		cancel()
+
+		if err == nil || streamed {
+			return resp, err
+		}
+
+		log.Warn().Err(err).Int("attempt", i+1).Int("providers", len(f.providers)).Msg("LLM stream failed, trying next fallback")
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
+
+func (f *FallbackProvider) GenerateStructured(ctx context.Context, prompt string, schema *Schema) (*Response, error) {
+	return f.try(ctx, func(ctx context.Context, p Provider) (*Response, error) {
+		return p.GenerateStructured(ctx, prompt, schema)
+	})
+}
+
+func (f *FallbackProvider) try(ctx context.Context, call func(context.Context, Provider) (*Response, error)) (*Response, error) {
+	if len(f.providers) == 0 {
+		return nil, errors.New("no LLM provider configured")
+	}
+
+	var errs []error
+	for i, p := range f.providers {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		attemptCtx, cancel := ctx, context.CancelFunc(func() {})
+		if f.timeout > 0 {
+			attemptCtx, cancel = context.WithTimeout(ctx, f.timeout)
+		}
+		resp, err := call(attemptCtx, p)
+		cancel()
+
+		if err == nil && resp != nil && resp.Text != "" {
+			return resp, nil
+		}
+		if err == nil {
+			err = ErrEmptyResponse
+		}
+
+		log.Warn().Err(err).Int("attempt", i+1).Int("providers", len(f.providers)).Msg("LLM call failed, trying next fallback")
+		errs = append(errs, err)
+	}
+
+	return nil, fmt.Errorf("all LLM providers failed: %w", errors.Join(errs...))
+}
@@ -4,16 +4,18 @@ import (
 	"context"
 	"fmt"
 	"minos/config"
+	"minos/internal/llm"
 
 	"github.com/google/generative-ai-go/genai"
 	"github.com/rs/zerolog/log"
+	"google.golang.org/api/iterator"
 	"google.golang.org/api/option"
 )
 
 type Client struct {
-	client *genai.Client
-	model  *genai.GenerativeModel
-	conf   *config.Config
+	client    *genai.Client
+	modelName string
+	conf      *config.Config
 }
 
 func NewClient(cfg *config.Config) (*Client, error) {
@@ -27,14 +29,13 @@ func NewClient(cfg *config.Config) (*Client, error) {
 	if modelName == "" {
 		modelName = "gemini-1.5-pro"
 	}
-	model := client.GenerativeModel(modelName)
 
 	log.Info().Str("model", modelName).Msg("Gemini client initialized")
 
 	return &Client{
-		client: client,
-		model:  model,
-		conf:   cfg,
+		client:    client,
+		modelName: modelName,
+		conf:      cfg,
 	}, nil
 }
 
@@ -42,15 +43,165 @@ func (c *Client) Close() {
 	c.client.Close()
 }
 
-func (c *Client) StartChat(history []*genai.Content) *genai.ChatSession {
-	cs := c.model.StartChat()
-	if len(history) > 0 {
-		cs.History = history
+// newModel returns a fresh model handle so per-call settings never leak
+// between concurrent requests.
+func (c *Client) newModel() *genai.GenerativeModel {
+	return c.client.GenerativeModel(c.modelName)
+}
+
+func (c *Client) Generate(ctx context.Context, prompt string) (*llm.Response, error) {
+	resp, err := c.newModel().GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return nil, err
+	}
+	return c.toResponse(resp)
+}
+
+// chatModel returns a model handle carrying the session's system instruction.
+func (c *Client) chatModel(system string) *genai.GenerativeModel {
+	model := c.newModel()
+	if system != "" {
+		model.SystemInstruction = genai.NewUserContent(genai.Text(system))
+	}
+	return model
+}
+
+func (c *Client) Chat(ctx context.Context, system string, history []llm.Message, message string) (*llm.Response, error) {
+	cs := c.chatModel(system).StartChat()
+	cs.History = toContents(history)
+	resp, err := cs.SendMessage(ctx, genai.Text(message))
+	if err != nil {
+		return nil, err
+	}
+	return c.toResponse(resp)
+}
+
+func (c *Client) ChatStream(ctx context.Context, system string, history []llm.Message, message string, onDelta llm.DeltaFunc) (*llm.Response, error) {
+	cs := c.chatModel(system).StartChat()
+	cs.History = toContents(history)
+	iter := cs.SendMessageStream(ctx, genai.Text(message))
+
+	out := &llm.Response{Model: c.modelName}
+	for {
+		resp, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return llm.Partial(out), err
+		}
+
+		delta := extractText(resp)
+		if resp.UsageMetadata != nil {
+			out.Usage = toUsage(resp.UsageMetadata)
+		}
+		if delta == "" {
+			continue
+		}
+		out.Text += delta
+		if err := onDelta(delta); err != nil {
+			return llm.Partial(out), err
+		}
+	}
+
+	if out.Text == "" {
+		return nil, llm.ErrEmptyResponse
+	}
+	return out, nil
+}
+
+func (c *Client) GenerateStructured(ctx context.Context, prompt string, schema *llm.Schema) (*llm.Response, error) {
+	model := c.newModel()
+	model.ResponseMIMEType = "application/json"
+	model.ResponseSchema = toSchema(schema)
+	resp, err := model.GenerateContent(ctx, genai.Text(prompt))
+	if err != nil {
+		return nil, err
 	}
-	return cs
+	return c.toResponse(resp)
 }
 
-func (c *Client) GenerateContent(ctx context.Context, prompt string) (*genai.GenerateContentResponse, error) {
-	return c.model.GenerateContent(ctx, genai.Text(prompt))
+// toSchema converts a provider-agnostic schema to Gemini's. Bounds have no
+// Gemini equivalent and are only enforced by validation afterwards.
+func toSchema(schema *llm.Schema) *genai.Schema {
+	if schema == nil {
+		return nil
+	}
+
+	out := &genai.Schema{
+		Description: schema.Description,
+		Nullable:    schema.Nullable,
+		Required:    schema.Required,
+		Items:       toSchema(schema.Items),
+	}
+	switch schema.Type {
+	case llm.SchemaObject:
+		out.Type = genai.TypeObject
+	case llm.SchemaArray:
+		out.Type = genai.TypeArray
+	case llm.SchemaInteger:
+		out.Type = genai.TypeInteger
+	case llm.SchemaNumber:
+		out.Type = genai.TypeNumber
+	case llm.SchemaBoolean:
+		out.Type = genai.TypeBoolean
+	default:
+		out.Type = genai.TypeString
+	}
+	if len(schema.Properties) > 0 {
+		out.Properties = make(map[string]*genai.Schema, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			out.Properties[name] = toSchema(prop)
+		}
+	}
+	return out
 }
 
+func toContents(history []llm.Message) []*genai.Content {
+	contents := make([]*genai.Content, 0, len(history))
+	for _, msg := range history {
+		contents = append(contents, &genai.Content{
+			Role:  string(msg.Role),
+			Parts: []genai.Part{genai.Text(msg.Content)},
+		})
+	}
+	return contents
+}
+
+func extractText(resp *genai.GenerateContentResponse) string {
+	if len(resp.Candidates) == 0 || resp.Candidates[0].Content == nil {
+		return ""
+	}
+
+	text := ""
+	for _, part := range resp.Candidates[0].Content.Parts {
+		if txt, ok := part.(genai.Text); ok {
+			text += string(txt)
+		}
+	}
+	return text
+}
+
+func toUsage(meta *genai.UsageMetadata) llm.Usage {
+	return llm.Usage{
+		PromptTokens:     int(meta.PromptTokenCount),
+		CompletionTokens: int(meta.CandidatesTokenCount),
+		TotalTokens:      int(meta.TotalTokenCount),
+	}
+}
+
+func (c *Client) toResponse(resp *genai.GenerateContentResponse) (*llm.Response, error) {
+	text := extractText(resp)
+	if text == "" {
+		return nil, llm.ErrEmptyResponse
+	}
+
+	out := &llm.Response{
+		Text:  text,
+		Model: c.modelName,
+	}
+	if resp.UsageMetadata != nil {
+		out.Usage = toUsage(resp.UsageMetadata)
+	}
+	return out, nil
+}
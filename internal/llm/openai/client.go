@@ -0,0 +1,285 @@
+package openai
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"minos/config"
+	"minos/internal/llm"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Client talks to any OpenAI chat-completions compatible endpoint (OpenAI, OpenRouter, ...).
+type Client struct {
+	httpClient   *http.Client
+	apiKey       string
+	baseURL      string
+	modelName    string
+	providerSort string
+}
+
+func NewClient(cfg *config.Config, modelName string) *Client {
+	log.Info().Str("model", modelName).Str("base_url", cfg.OpenAI.BaseURL).Msg("OpenAI-compatible client initialized")
+
+	return &Client{
+		httpClient:   &http.Client{},
+		apiKey:       cfg.OpenAI.ApiKey,
+		baseURL:      strings.TrimSuffix(cfg.OpenAI.BaseURL, "/"),
+		modelName:    modelName,
+		providerSort: cfg.OpenAI.ProviderSort,
+	}
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type responseFormat struct {
+	Type       string      `json:"type"`
+	JSONSchema *jsonSchema `json:"json_schema,omitempty"`
+}
+
+type jsonSchema struct {
+	Name   string      `json:"name"`
+	Schema *llm.Schema `json:"schema"`
+	Strict bool        `json:"strict"`
+}
+
+type providerPreferences struct {
+	Sort string `json:"sort,omitempty"`
+}
+
+type streamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type chatRequest struct {
+	Model          string               `json:"model"`
+	Messages       []chatMessage        `json:"messages"`
+	ResponseFormat *responseFormat      `json:"response_format,omitempty"`
+	Provider       *providerPreferences `json:"provider,omitempty"`
+	Stream         bool                 `json:"stream,omitempty"`
+	StreamOptions  *streamOptions       `json:"stream_options,omitempty"`
+}
+
+type usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+func (u usage) toUsage() llm.Usage {
+	return llm.Usage{
+		PromptTokens:     u.PromptTokens,
+		CompletionTokens: u.CompletionTokens,
+		TotalTokens:      u.TotalTokens,
+	}
+}
+
+type apiError struct {
+	Message string `json:"message"`
+}
+
+type chatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Usage usage     `json:"usage"`
+	Error *apiError `json:"error,omitempty"`
+}
+
+type streamChunk struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Delta chatMessage `json:"delta"`
+	} `json:"choices"`
+	Usage *usage    `json:"usage,omitempty"`
+	Error *apiError `json:"error,omitempty"`
+}
+
+func (c *Client) Generate(ctx context.Context, prompt string) (*llm.Response, error) {
+	return c.complete(ctx, []chatMessage{{Role: "user", Content: prompt}}, nil)
+}
+
+func (c *Client) Chat(ctx context.Context, system string, history []llm.Message, message string) (*llm.Response, error) {
+	messages := toMessages(system, history)
+	messages = append(messages, chatMessage{Role: "user", Content: message})
+	return c.complete(ctx, messages, nil)
+}
+
+func (c *Client) ChatStream(ctx context.Context, system string, history []llm.Message, message string, onDelta llm.DeltaFunc) (*llm.Response, error) {
+	messages := toMessages(system, history)
+	messages = append(messages, chatMessage{Role: "user", Content: message})
+
+	reqBody := c.newRequest(messages, nil)
+	reqBody.Stream = true
+	reqBody.StreamOptions = &streamOptions{IncludeUsage: true}
+
+	httpResp, err := c.do(ctx, reqBody)
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	if httpResp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(httpResp.Body)
+		return nil, c.statusError(httpResp.StatusCode, body)
+	}
+
+	out := &llm.Response{Model: c.modelName}
+	scanner := bufio.NewScanner(httpResp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, "data:") {
+			// Blank separators and SSE comments such as OpenRouter keep-alives
+			continue
+		}
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			break
+		}
+
+		var chunk streamChunk
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return llm.Partial(out), fmt.Errorf("failed to decode stream chunk from %s: %w", c.modelName, err)
+		}
+		if chunk.Error != nil {
+			return llm.Partial(out), fmt.Errorf("model %s stream error: %s", c.modelName, chunk.Error.Message)
+		}
+		if chunk.Model != "" {
+			out.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			out.Usage = chunk.Usage.toUsage()
+		}
+		if len(chunk.Choices) == 0 || chunk.Choices[0].Delta.Content == "" {
+			continue
+		}
+
+		delta := chunk.Choices[0].Delta.Content
+		out.Text += delta
+		if err := onDelta(delta); err != nil {
+			return llm.Partial(out), err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return llm.Partial(out), err
+	}
+
+	if out.Text == "" {
+		return nil, llm.ErrEmptyResponse
+	}
+	return out, nil
+}
+
+// GenerateStructured uses json_schema response format when a schema is given.
+// Strict mode is off because it forbids optional properties; the reply is
+// validated by the caller instead.
+func (c *Client) GenerateStructured(ctx context.Context, prompt string, schema *llm.Schema) (*llm.Response, error) {
+	format := &responseFormat{Type: "json_object"}
+	if schema != nil {
+		format = &responseFormat{
+			Type:       "json_schema",
+			JSONSchema: &jsonSchema{Name: "response", Schema: schema},
+		}
+	}
+	return c.complete(ctx, []chatMessage{{Role: "user", Content: prompt}}, format)
+}
+
+// toMessages converts a history, led by the system instruction as a system
+// message when there is one.
+func toMessages(system string, history []llm.Message) []chatMessage {
+	messages := make([]chatMessage, 0, len(history)+2)
+	if system != "" {
+		messages = append(messages, chatMessage{Role: "system", Content: system})
+	}
+	for _, msg := range history {
+		role := "user"
+		if msg.Role == llm.RoleModel {
+			role = "assistant"
+		}
+		messages = append(messages, chatMessage{Role: role, Content: msg.Content})
+	}
+	return messages
+}
+
+func (c *Client) newRequest(messages []chatMessage, format *responseFormat) chatRequest {
+	reqBody := chatRequest{
+		Model:          c.modelName,
+		Messages:       messages,
+		ResponseFormat: format,
+	}
+	if c.providerSort != "" {
+		reqBody.Provider = &providerPreferences{Sort: c.providerSort}
+	}
+	return reqBody
+}
+
+func (c *Client) do(ctx context.Context, reqBody chatRequest) (*http.Response, error) {
+	payload, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+
+	return c.httpClient.Do(req)
+}
+
+func (c *Client) statusError(status int, body []byte) error {
+	var resp chatResponse
+	if err := json.Unmarshal(body, &resp); err == nil && resp.Error != nil {
+		return fmt.Errorf("model %s returned status %d: %s", c.modelName, status, resp.Error.Message)
+	}
+	return fmt.Errorf("model %s returned status %d", c.modelName, status)
+}
+
+func (c *Client) complete(ctx context.Context, messages []chatMessage, format *responseFormat) (*llm.Response, error) {
+	httpResp, err := c.do(ctx, c.newRequest(messages, format))
+	if err != nil {
+		return nil, err
+	}
+	defer httpResp.Body.Close()
+
+	body, err := io.ReadAll(httpResp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if httpResp.StatusCode != http.StatusOK {
+		return nil, c.statusError(httpResp.StatusCode, body)
+	}
+
+	var resp chatResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("failed to decode response from %s: %w", c.modelName, err)
+	}
+
+	if len(resp.Choices) == 0 || resp.Choices[0].Message.Content == "" {
+		return nil, llm.ErrEmptyResponse
+	}
+
+	modelName := resp.Model
+	if modelName == "" {
+		modelName = c.modelName
+	}
+
+	return &llm.Response{
+		Text:  resp.Choices[0].Message.Content,
+		Model: modelName,
+		Usage: resp.Usage.toUsage(),
+	}, nil
+}
@@ -0,0 +1,40 @@
+package llm
+
+import (
+	"minos/config"
+	"strings"
+)
+
+// PriceTable maps model names to their price in USD per million tokens.
+type PriceTable map[string]config.ModelPrice
+
+func NewPriceTable(cfg *config.Config) PriceTable {
+	return PriceTable(cfg.LLM.Prices)
+}
+
+// Cost returns the USD cost of usage on model, or 0 when the model has no
+// price. A model matches its exact entry first, then the longest entry it
+// starts with, so "gemini-2.0-flash" also prices "gemini-2.0-flash-001".
+func (t PriceTable) Cost(model string, usage Usage) float64 {
+	price, ok := t.lookup(model)
+	if !ok {
+		return 0
+	}
+	return (float64(usage.PromptTokens)*price.Input + float64(usage.CompletionTokens)*price.Output) / 1e6
+}
+
+func (t PriceTable) lookup(model string) (config.ModelPrice, bool) {
+	if price, ok := t[model]; ok {
+		return price, true
+	}
+	var best string
+	for name := range t {
+		if strings.HasPrefix(model, name) && len(name) > len(best) {
+			best = name
+		}
+	}
+	if best == "" {
+		return config.ModelPrice{}, false
+	}
+	return t[best], true
+}
@@ -0,0 +1,47 @@
+package llm
+
+import (
+	"math"
+	"testing"
+
+	"minos/config"
+)
+
+func TestPriceTableCost(t *testing.T) {
+	prices := PriceTable{
+		"gemini-2.0-flash":      {Input: 0.10, Output: 0.40},
+		"gemini-2.0-flash-lite": {Input: 0.075, Output: 0.30},
+		"gpt-4o":                {Input: 2.50, Output: 10.00},
+	}
+	usage := Usage{PromptTokens: 1_000_000, CompletionTokens: 500_000, TotalTokens: 1_500_000}
+
+	tests := []struct {
+		model string
+		want  float64
+	}{
+		{"gemini-2.0-flash", 0.30},
+		{"gemini-2.0-flash-001", 0.30},
+		{"gemini-2.0-flash-lite-001", 0.225},
+		{"gpt-4o", 7.50},
+		{"gpt-4", 0},
+		{"claude", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.model, func(t *testing.T) {
+			if got := prices.Cost(tt.model, usage); math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Cost(%q) = %v, want %v", tt.model, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPriceTableCostWithoutPrices(t *testing.T) {
+	var prices PriceTable
+	if got := prices.Cost("gemini-2.0-flash", Usage{PromptTokens: 100, CompletionTokens: 100}); got != 0 {
+		t.Errorf("Cost = %v, want 0", got)
+	}
+	prices = PriceTable{"gemini-2.0-flash": config.ModelPrice{Input: 1, Output: 1}}
+	if got := prices.Cost("gemini-2.0-flash", Usage{}); got != 0 {
+		t.Errorf("Cost of no usage = %v, want 0", got)
+	}
+}
@@ -1,5 +1,14 @@
 package llm
 
+// Prompt template names looked up in the prompt_templates table.
+const (
+	PromptNameInterviewer = "interviewer"
+	PromptNameReviewer    = "reviewer"
+	PromptNameEvaluator   = "evaluator"
+	PromptNameSummarizer  = "summarizer"
+)
+
+// Built-in prompts, used when no active template exists for a name.
 const (
 	SystemPromptInterviewer = `You are a senior software engineer conducting a coding interview.
 Your role:
@@ -9,7 +18,7 @@ Your role:
 - Evaluate their communication and problem-solving process.
 
 Problem Context:
-%s
+{{.problem}}
 
 Rules:
 - Be encouraging but professional.
@@ -21,10 +30,10 @@ Rules:
 	SystemPromptReviewer = `Role: Senior Technical Interviewer
 Task: Review the candidate's code for the given problem.
 
-Problem: %s
+Problem: {{.problem}}
+Language: {{.language}}
 Code:
-%s
-%s
+{{.code}}
 
 Evaluate:
 1. Logic correctness (Does it solve the problem?)
@@ -44,11 +53,11 @@ Output JSON:
 
 	SystemPromptEvaluator = `Evaluate this coding interview transcript.
 
-Problem: %s
+Problem: {{.problem}}
 Transcript:
-%s
+{{.transcript}}
 Code Submissions:
-%s
+{{.submissions}}
 
 Score each dimension (0-10):
 1. Problem Solving: Algorithm choice, optimization, edge cases
@@ -61,5 +70,42 @@ Provide:
 - Top 3 strengths
 - Top 3 areas for improvement
 - Detailed feedback paragraph
+`
+
+	SystemPromptSummarizer = `You keep the running notes of a coding interview so the interviewer can continue it without the full transcript.
+
+Problem: {{.problem}}
+
+Notes so far:
+{{.summary}}
+
+New part of the transcript:
+{{.transcript}}
+
+Rewrite the notes to cover everything so far. Keep:
+- The candidate's approach, how it evolved and the reasoning they gave
+- Questions asked, hints given and how the candidate responded to them
+- Bugs, edge cases and complexity discussed, and what is still open
+- Anything the interviewer promised or asked the candidate to do next
+
+Code the candidate attached is kept separately; refer to it, do not copy it.
+Write plain concise prose or bullets, at most 300 words, with no preamble.
 `
 )
+
+// DefaultPrompts maps each prompt name to its built-in fallback.
+var DefaultPrompts = map[string]string{
+	PromptNameInterviewer: SystemPromptInterviewer,
+	PromptNameReviewer:    SystemPromptReviewer,
+	PromptNameEvaluator:   SystemPromptEvaluator,
+	PromptNameSummarizer:  SystemPromptSummarizer,
+}
+
+// DefaultPromptVariables declares the variables each built-in prompt expects.
+// Templates stored under the same names are rendered with the same values.
+var DefaultPromptVariables = map[string]string{
+	PromptNameInterviewer: `{"problem": "string"}`,
+	PromptNameReviewer:    `{"problem": "string", "code": "string", "language": "string"}`,
+	PromptNameEvaluator:   `{"problem": "string", "transcript": "string", "submissions": "string"}`,
+	PromptNameSummarizer:  `{"problem": "string", "summary": "string", "transcript": "string"}`,
+}
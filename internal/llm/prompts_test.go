@@ -0,0 +1,38 @@
+package llm
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDefaultPromptsRenderWithDefaultVariables(t *testing.T) {
+	for name, content := range DefaultPrompts {
+		t.Run(name, func(t *testing.T) {
+			raw, ok := DefaultPromptVariables[name]
+			if !ok {
+				t.Fatalf("no default variables for prompt %q", name)
+			}
+			schema, err := ParseVariables(raw)
+			if err != nil {
+				t.Fatalf("ParseVariables: %v", err)
+			}
+			if err := ValidateTemplate(content, schema); err != nil {
+				t.Fatalf("ValidateTemplate: %v", err)
+			}
+
+			values := make(map[string]interface{}, len(schema))
+			for variable := range schema {
+				values[variable] = "<" + variable + ">"
+			}
+			rendered, err := Render(content, schema, values)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			for variable := range schema {
+				if !strings.Contains(rendered, "<"+variable+">") {
+					t.Errorf("rendered prompt does not contain %q", variable)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,65 @@
+package llm
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrEmptyResponse is returned when the model answers without any text.
+var ErrEmptyResponse = errors.New("empty response from AI")
+
+type Role string
+
+const (
+	RoleUser  Role = "user"
+	RoleModel Role = "model"
+)
+
+// Message is a single turn of a provider-agnostic chat history.
+type Message struct {
+	Role    Role
+	Content string
+}
+
+// Usage holds the token counts reported by the model for one call.
+type Usage struct {
+	PromptTokens     int
+	CompletionTokens int
+	TotalTokens      int
+}
+
+// Response is the provider-agnostic result of a generation call.
+type Response struct {
+	Text  string
+	Model string
+	Usage Usage
+}
+
+// Partial returns the text streamed so far, or nil when nothing was produced.
+func Partial(resp *Response) *Response {
+	if resp == nil || resp.Text == "" {
+		return nil
+	}
+	return resp
+}
+
+// DeltaFunc receives each chunk of text as it is streamed from the model.
+// Returning an error aborts the stream.
+type DeltaFunc func(delta string) error
+
+// Provider is implemented by every LLM backend used by the services.
+type Provider interface {
+	// Generate runs a single prompt without any history.
+	Generate(ctx context.Context, prompt string) (*Response, error)
+	// Chat sends message on top of the given history. system is passed as the
+	// backend's native system instruction; it is omitted when empty.
+	Chat(ctx context.Context, system string, history []Message, message string) (*Response, error)
+	// ChatStream behaves like Chat but calls onDelta for every chunk as it arrives.
+	// When the stream breaks after some text was produced, the partial Response
+	// is returned together with the error.
+	ChatStream(ctx context.Context, system string, history []Message, message string, onDelta DeltaFunc) (*Response, error)
+	// GenerateStructured runs a single prompt asking the model to answer with
+	// JSON matching schema, using the backend's native structured output mode.
+	// The reply is not validated here; see GenerateValidated.
+	GenerateStructured(ctx context.Context, prompt string, schema *Schema) (*Response, error)
+}
@@ -0,0 +1,251 @@
+package llm
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// ErrInvalidStructuredOutput is returned when the model keeps answering with
+// JSON that does not match the requested schema.
+var ErrInvalidStructuredOutput = errors.New("invalid structured output from AI")
+
+// Schema types, a subset of JSON Schema understood by every provider.
+const (
+	SchemaObject  = "object"
+	SchemaArray   = "array"
+	SchemaString  = "string"
+	SchemaInteger = "integer"
+	SchemaNumber  = "number"
+	SchemaBoolean = "boolean"
+)
+
+// Schema describes the JSON value a structured call must return.
+type Schema struct {
+	Type        string             `json:"type"`
+	Description string             `json:"description,omitempty"`
+	Properties  map[string]*Schema `json:"properties,omitempty"`
+	Required    []string           `json:"required,omitempty"`
+	Items       *Schema            `json:"items,omitempty"`
+	Minimum     *float64           `json:"minimum,omitempty"`
+	Maximum     *float64           `json:"maximum,omitempty"`
+	Nullable    bool               `json:"-"`
+}
+
+// Validate checks a decoded JSON value (as produced by json.Unmarshal into
+// interface{}) against the schema and reports the first mismatch with its path.
+func (s *Schema) Validate(value interface{}) error {
+	return s.validate("$", value)
+}
+
+func (s *Schema) validate(path string, value interface{}) error {
+	if value == nil {
+		if s.Nullable {
+			return nil
+		}
+		return fmt.Errorf("%s: expected %s, got null", path, s.Type)
+	}
+
+	switch s.Type {
+	case SchemaObject:
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected object, got %s", path, jsonType(value))
+		}
+		for _, name := range s.Required {
+			if _, ok := obj[name]; !ok {
+				return fmt.Errorf("%s: missing required property '%s'", path, name)
+			}
+		}
+		names := make([]string, 0, len(s.Properties))
+		for name := range s.Properties {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			if v, ok := obj[name]; ok {
+				if err := s.Properties[name].validate(path+"."+name, v); err != nil {
+					return err
+				}
+			}
+		}
+	case SchemaArray:
+		arr, ok := value.([]interface{})
+		if !ok {
+			return fmt.Errorf("%s: expected array, got %s", path, jsonType(value))
+		}
+		if s.Items != nil {
+			for i, v := range arr {
+				if err := s.Items.validate(fmt.Sprintf("%s[%d]", path, i), v); err != nil {
+					return err
+				}
+			}
+		}
+	case SchemaString:
+		if _, ok := value.(string); !ok {
+			return fmt.Errorf("%s: expected string, got %s", path, jsonType(value))
+		}
+	case SchemaBoolean:
+		if _, ok := value.(bool); !ok {
+			return fmt.Errorf("%s: expected boolean, got %s", path, jsonType(value))
+		}
+	case SchemaInteger, SchemaNumber:
+		n, ok := value.(float64)
+		if !ok {
+			return fmt.Errorf("%s: expected %s, got %s", path, s.Type, jsonType(value))
+		}
+		if s.Type == SchemaInteger && n != math.Trunc(n) {
+			return fmt.Errorf("%s: expected integer, got %v", path, n)
+		}
+		if s.Minimum != nil && n < *s.Minimum {
+			return fmt.Errorf("%s: %v is below the minimum %v", path, n, *s.Minimum)
+		}
+		if s.Maximum != nil && n > *s.Maximum {
+			return fmt.Errorf("%s: %v is above the maximum %v", path, n, *s.Maximum)
+		}
+	}
+	return nil
+}
+
+func jsonType(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		return "number"
+	case bool:
+		return "boolean"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// ExtractJSON pulls the JSON document out of a model reply that may wrap it in
+// markdown fences or surround it with prose. It returns the first balanced
+// object or array found.
+func ExtractJSON(text string) (string, error) {
+	text = strings.TrimSpace(text)
+	if json.Valid([]byte(text)) {
+		return text, nil
+	}
+
+	start := strings.IndexAny(text, "{[")
+	for start >= 0 {
+		if end := matchBracket(text, start); end > start {
+			candidate := text[start : end+1]
+			if json.Valid([]byte(candidate)) {
+				return candidate, nil
+			}
+		}
+		next := strings.IndexAny(text[start+1:], "{[")
+		if next < 0 {
+			break
+		}
+		start += next + 1
+	}
+	return "", errors.New("no JSON document found in reply")
+}
+
+// matchBracket returns the index of the bracket closing the one at start,
+// skipping over string literals, or -1.
+func matchBracket(text string, start int) int {
+	depth := 0
+	inString := false
+	escaped := false
+	for i := start; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+		case '}', ']':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return -1
+}
+
+// GenerateValidated asks the provider for output matching schema and decodes
+// it into out. Replies that cannot be parsed or fail validation are re-asked,
+// quoting the problem back to the model, up to maxAttempts calls in total.
+// The last Response is returned even on failure so callers can record usage;
+// its Usage covers every attempt.
+func GenerateValidated(ctx context.Context, p Provider, prompt string, schema *Schema, out interface{}, maxAttempts int) (*Response, error) {
+	if maxAttempts < 1 {
+		maxAttempts = 1
+	}
+
+	request := prompt
+	var resp *Response
+	var lastErr error
+	var usage Usage
+	for attempt := 1; attempt <= maxAttempts; attempt++ {
+		var err error
+		resp, err = p.GenerateStructured(ctx, request, schema)
+		if resp != nil {
+			// Report what every attempt cost, not just the last one
+			usage.PromptTokens += resp.Usage.PromptTokens
+			usage.CompletionTokens += resp.Usage.CompletionTokens
+			usage.TotalTokens += resp.Usage.TotalTokens
+			resp.Usage = usage
+		}
+		if err != nil {
+			return resp, err
+		}
+
+		lastErr = decodeValidated(resp.Text, schema, out)
+		if lastErr == nil {
+			return resp, nil
+		}
+
+		log.Warn().Err(lastErr).Int("attempt", attempt).Int("max_attempts", maxAttempts).Msg("Structured output failed validation")
+		request = fmt.Sprintf("%s\n\nYour previous reply was rejected: %s.\nReply again with only a single JSON value that matches the requested schema, without markdown or commentary.", prompt, lastErr)
+	}
+
+	return resp, fmt.Errorf("%w: %v", ErrInvalidStructuredOutput, lastErr)
+}
+
+func decodeValidated(text string, schema *Schema, out interface{}) error {
+	doc, err := ExtractJSON(text)
+	if err != nil {
+		return err
+	}
+
+	var generic interface{}
+	if err := json.Unmarshal([]byte(doc), &generic); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	if schema != nil {
+		if err := schema.Validate(generic); err != nil {
+			return err
+		}
+	}
+	if err := json.Unmarshal([]byte(doc), out); err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	return nil
+}
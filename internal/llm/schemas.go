@@ -0,0 +1,60 @@
+package llm
+
+// Output schemas for the structured prompts. Templates stored under the same
+// names must keep producing these shapes.
+
+func bound(v float64) *float64 {
+	return &v
+}
+
+// score is a 0-10 integer, the scale used by every evaluation dimension.
+func score(description string) *Schema {
+	return &Schema{Type: SchemaInteger, Description: description, Minimum: bound(0), Maximum: bound(10)}
+}
+
+func stringList(description string) *Schema {
+	return &Schema{Type: SchemaArray, Description: description, Items: &Schema{Type: SchemaString}}
+}
+
+// EvaluationSchema is the output of the evaluator prompt.
+var EvaluationSchema = &Schema{
+	Type: SchemaObject,
+	Properties: map[string]*Schema{
+		"problem_solving_score": score("Algorithm choice, optimization, edge cases"),
+		"code_quality_score":    score("Readability, naming, structure, best practices"),
+		"communication_score":   score("Clarity, asking questions, explaining approach"),
+		"technical_score":       score("Language mastery, CS fundamentals"),
+		"overall_score":         score("Weighted average of the other scores"),
+		"strengths":             stringList("Top strengths"),
+		"improvements":          stringList("Top areas for improvement"),
+		"detailed_feedback":     {Type: SchemaString, Description: "Detailed feedback paragraph"},
+	},
+	Required: []string{
+		"problem_solving_score", "code_quality_score", "communication_score", "technical_score",
+		"overall_score", "strengths", "improvements", "detailed_feedback",
+	},
+}
+
+// ReviewSchema is the output of the reviewer prompt.
+var ReviewSchema = &Schema{
+	Type: SchemaObject,
+	Properties: map[string]*Schema{
+		"is_correct":  {Type: SchemaBoolean, Nullable: true},
+		"feedback":    {Type: SchemaString},
+		"complexity":  {Type: SchemaString},
+		"suggestions": stringList("Improvements to the code"),
+		"simulated_results": {
+			Type: SchemaArray,
+			Items: &Schema{
+				Type: SchemaObject,
+				Properties: map[string]*Schema{
+					"input":    {Type: SchemaString},
+					"expected": {Type: SchemaString},
+					"actual":   {Type: SchemaString},
+					"passed":   {Type: SchemaBoolean},
+				},
+			},
+		},
+	},
+	Required: []string{"is_correct", "feedback", "complexity", "suggestions"},
+}
@@ -0,0 +1,311 @@
+package llm
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"text/template"
+	"text/template/parse"
+)
+
+// Variable types accepted in a prompt template's Variables schema.
+const (
+	VarTypeString  = "string"
+	VarTypeNumber  = "number"
+	VarTypeInteger = "integer"
+	VarTypeBoolean = "boolean"
+	VarTypeArray   = "array"
+	VarTypeObject  = "object"
+)
+
+// Variable declares one named input of a prompt template.
+type Variable struct {
+	Type        string      `json:"type"`
+	Required    bool        `json:"required"`
+	Description string      `json:"description,omitempty"`
+	Default     interface{} `json:"default,omitempty"`
+}
+
+// VariableSchema maps variable names to their declaration.
+//
+// It is stored as JSON in PromptTemplate.Variables. Each entry is either a
+// full declaration or a bare type name, which is shorthand for a required
+// variable of that type:
+//
+//	{"code": "string", "hints": {"type": "integer", "required": false, "default": 0}}
+type VariableSchema map[string]Variable
+
+// TemplateError describes why a template or a set of values was rejected.
+type TemplateError struct {
+	Syntax     string            `json:"syntax,omitempty"`
+	Undeclared []string          `json:"undeclared,omitempty"` // Referenced in content but not in the schema
+	Missing    []string          `json:"missing,omitempty"`    // Required but not supplied
+	Extra      []string          `json:"extra,omitempty"`      // Supplied but not declared
+	Invalid    map[string]string `json:"invalid,omitempty"`    // Wrong type or bad declaration
+}
+
+func (e *TemplateError) Error() string {
+	var parts []string
+	if e.Syntax != "" {
+		parts = append(parts, "syntax error: "+e.Syntax)
+	}
+	if len(e.Undeclared) > 0 {
+		parts = append(parts, "undeclared variables: "+strings.Join(e.Undeclared, ", "))
+	}
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing variables: "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Extra) > 0 {
+		parts = append(parts, "unexpected variables: "+strings.Join(e.Extra, ", "))
+	}
+	if len(e.Invalid) > 0 {
+		names := sortedKeys(e.Invalid)
+		for _, name := range names {
+			parts = append(parts, fmt.Sprintf("%s: %s", name, e.Invalid[name]))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (e *TemplateError) empty() bool {
+	return e.Syntax == "" && len(e.Undeclared) == 0 && len(e.Missing) == 0 && len(e.Extra) == 0 && len(e.Invalid) == 0
+}
+
+// ParseVariables decodes a Variables schema. An empty string is an empty schema.
+func ParseVariables(raw string) (VariableSchema, error) {
+	schema := VariableSchema{}
+	if strings.TrimSpace(raw) == "" {
+		return schema, nil
+	}
+
+	var entries map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(raw), &entries); err != nil {
+		return nil, &TemplateError{Syntax: "variables must be a JSON object: " + err.Error()}
+	}
+
+	tmplErr := &TemplateError{Invalid: map[string]string{}}
+	for name, entry := range entries {
+		var v Variable
+		var shorthand string
+		if err := json.Unmarshal(entry, &shorthand); err == nil {
+			v = Variable{Type: shorthand, Required: true}
+		} else if err := json.Unmarshal(entry, &v); err != nil {
+			tmplErr.Invalid[name] = "declaration must be a type name or an object"
+			continue
+		}
+		if !validType(v.Type) {
+			tmplErr.Invalid[name] = fmt.Sprintf("unknown type '%s'", v.Type)
+			continue
+		}
+		if v.Default != nil {
+			if msg := checkType(v.Type, v.Default); msg != "" {
+				tmplErr.Invalid[name] = "default " + msg
+				continue
+			}
+		}
+		schema[name] = v
+	}
+	if !tmplErr.empty() {
+		return nil, tmplErr
+	}
+	return schema, nil
+}
+
+// ValidateTemplate checks that content parses and only references declared variables.
+func ValidateTemplate(content string, schema VariableSchema) error {
+	tmpl, err := parseTemplate(content)
+	if err != nil {
+		return &TemplateError{Syntax: err.Error()}
+	}
+
+	tmplErr := &TemplateError{}
+	for _, name := range referencedVariables(tmpl) {
+		if _, ok := schema[name]; !ok {
+			tmplErr.Undeclared = append(tmplErr.Undeclared, name)
+		}
+	}
+	if !tmplErr.empty() {
+		return tmplErr
+	}
+	return nil
+}
+
+// Render executes content with the given values after checking them against schema.
+func Render(content string, schema VariableSchema, values map[string]interface{}) (string, error) {
+	tmpl, err := parseTemplate(content)
+	if err != nil {
+		return "", &TemplateError{Syntax: err.Error()}
+	}
+
+	tmplErr := &TemplateError{Invalid: map[string]string{}}
+	data := make(map[string]interface{}, len(schema))
+	for name, v := range schema {
+		value, ok := values[name]
+		if !ok || value == nil {
+			if v.Required {
+				tmplErr.Missing = append(tmplErr.Missing, name)
+				continue
+			}
+			value = v.Default
+			if value == nil {
+				value = zeroValue(v.Type)
+			}
+		} else if msg := checkType(v.Type, value); msg != "" {
+			tmplErr.Invalid[name] = msg
+			continue
+		}
+		data[name] = value
+	}
+	for name := range values {
+		if _, ok := schema[name]; !ok {
+			tmplErr.Extra = append(tmplErr.Extra, name)
+		}
+	}
+	if !tmplErr.empty() {
+		sort.Strings(tmplErr.Missing)
+		sort.Strings(tmplErr.Extra)
+		return "", tmplErr
+	}
+
+	var out strings.Builder
+	if err := tmpl.Execute(&out, data); err != nil {
+		return "", err
+	}
+	return out.String(), nil
+}
+
+func parseTemplate(content string) (*template.Template, error) {
+	return template.New("prompt").Option("missingkey=error").Parse(content)
+}
+
+// referencedVariables lists the top-level fields ({{.name}}) used by the
+// template. Bodies of range/with are skipped because dot is rebound there;
+// $.name is still collected anywhere.
+func referencedVariables(tmpl *template.Template) []string {
+	seen := map[string]bool{}
+	var walk func(node parse.Node, topLevel bool)
+	walk = func(node parse.Node, topLevel bool) {
+		switch n := node.(type) {
+		case *parse.ListNode:
+			if n == nil {
+				return
+			}
+			for _, child := range n.Nodes {
+				walk(child, topLevel)
+			}
+		case *parse.ActionNode:
+			walk(n.Pipe, topLevel)
+		case *parse.PipeNode:
+			if n == nil {
+				return
+			}
+			for _, cmd := range n.Cmds {
+				for _, arg := range cmd.Args {
+					walk(arg, topLevel)
+				}
+			}
+		case *parse.FieldNode:
+			if topLevel && len(n.Ident) > 0 {
+				seen[n.Ident[0]] = true
+			}
+		case *parse.VariableNode:
+			if len(n.Ident) > 1 && n.Ident[0] == "$" {
+				seen[n.Ident[1]] = true
+			}
+		case *parse.ChainNode:
+			walk(n.Node, topLevel)
+		case *parse.IfNode:
+			walk(n.Pipe, topLevel)
+			walk(n.List, topLevel)
+			walk(n.ElseList, topLevel)
+		case *parse.RangeNode:
+			walk(n.Pipe, topLevel)
+			walk(n.List, false)
+			walk(n.ElseList, topLevel)
+		case *parse.WithNode:
+			walk(n.Pipe, topLevel)
+			walk(n.List, false)
+			walk(n.ElseList, topLevel)
+		case *parse.TemplateNode:
+			walk(n.Pipe, topLevel)
+		}
+	}
+	if tmpl.Tree != nil {
+		walk(tmpl.Tree.Root, true)
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func validType(t string) bool {
+	switch t {
+	case VarTypeString, VarTypeNumber, VarTypeInteger, VarTypeBoolean, VarTypeArray, VarTypeObject:
+		return true
+	}
+	return false
+}
+
+// checkType validates a JSON-decoded value against a declared type and
+// returns a message when it does not match.
+func checkType(t string, value interface{}) string {
+	ok := false
+	switch t {
+	case VarTypeString:
+		_, ok = value.(string)
+	case VarTypeNumber:
+		switch value.(type) {
+		case float64, float32, int, int64:
+			ok = true
+		}
+	case VarTypeInteger:
+		switch v := value.(type) {
+		case int, int64:
+			ok = true
+		case float64:
+			ok = v == math.Trunc(v)
+		}
+	case VarTypeBoolean:
+		_, ok = value.(bool)
+	case VarTypeArray:
+		switch value.(type) {
+		case []interface{}, []string:
+			ok = true
+		}
+	case VarTypeObject:
+		_, ok = value.(map[string]interface{})
+	}
+	if !ok {
+		return fmt.Sprintf("expected %s, got %T", t, value)
+	}
+	return ""
+}
+
+func zeroValue(t string) interface{} {
+	switch t {
+	case VarTypeNumber, VarTypeInteger:
+		return 0
+	case VarTypeBoolean:
+		return false
+	case VarTypeArray:
+		return []interface{}{}
+	case VarTypeObject:
+		return map[string]interface{}{}
+	}
+	return ""
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
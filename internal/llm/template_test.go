@@ -0,0 +1,123 @@
+package llm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestParseVariables(t *testing.T) {
+	schema, err := ParseVariables(`{"code": "string", "hints": {"type": "integer", "required": false, "default": 2}}`)
+	if err != nil {
+		t.Fatalf("ParseVariables: %v", err)
+	}
+	want := VariableSchema{
+		"code":  {Type: VarTypeString, Required: true},
+		"hints": {Type: VarTypeInteger, Default: float64(2)},
+	}
+	if !reflect.DeepEqual(schema, want) {
+		t.Errorf("ParseVariables = %#v, want %#v", schema, want)
+	}
+
+	if schema, err := ParseVariables("  "); err != nil || len(schema) != 0 {
+		t.Errorf("ParseVariables(blank) = %v, %v; want empty schema", schema, err)
+	}
+}
+
+func TestParseVariablesRejectsBadDeclarations(t *testing.T) {
+	_, err := ParseVariables(`{"a": "text", "b": 3, "c": {"type": "boolean", "default": "yes"}, "d": "string"}`)
+	var tmplErr *TemplateError
+	if !errors.As(err, &tmplErr) {
+		t.Fatalf("ParseVariables error = %v, want a TemplateError", err)
+	}
+	for _, name := range []string{"a", "b", "c"} {
+		if _, ok := tmplErr.Invalid[name]; !ok {
+			t.Errorf("variable %q not reported as invalid", name)
+		}
+	}
+	if _, ok := tmplErr.Invalid["d"]; ok {
+		t.Errorf("valid variable %q reported as invalid", "d")
+	}
+
+	if _, err := ParseVariables(`["code"]`); !errors.As(err, &tmplErr) || tmplErr.Syntax == "" {
+		t.Errorf("ParseVariables(array) error = %v, want a syntax error", err)
+	}
+}
+
+func TestValidateTemplate(t *testing.T) {
+	schema := VariableSchema{"problem": {Type: VarTypeString, Required: true}, "items": {Type: VarTypeArray}}
+
+	valid := `{{.problem}}{{range .items}}{{.name}} {{$.problem}}{{end}}`
+	if err := ValidateTemplate(valid, schema); err != nil {
+		t.Errorf("ValidateTemplate(%q) = %v", valid, err)
+	}
+
+	err := ValidateTemplate(`{{.problem}} {{.code}}{{with .items}}{{$.language}}{{end}}`, schema)
+	var tmplErr *TemplateError
+	if !errors.As(err, &tmplErr) {
+		t.Fatalf("ValidateTemplate error = %v, want a TemplateError", err)
+	}
+	if want := []string{"code", "language"}; !reflect.DeepEqual(tmplErr.Undeclared, want) {
+		t.Errorf("Undeclared = %v, want %v", tmplErr.Undeclared, want)
+	}
+
+	if err := ValidateTemplate(`{{.problem`, schema); !errors.As(err, &tmplErr) || tmplErr.Syntax == "" {
+		t.Errorf("ValidateTemplate(unclosed action) error = %v, want a syntax error", err)
+	}
+}
+
+func TestRender(t *testing.T) {
+	schema, err := ParseVariables(`{
+		"name": "string",
+		"hints": {"type": "integer", "required": false, "default": 2},
+		"strict": {"type": "boolean", "required": false}
+	}`)
+	if err != nil {
+		t.Fatalf("ParseVariables: %v", err)
+	}
+	content := `{{.name}}:{{.hints}}:{{.strict}}`
+
+	tests := []struct {
+		name   string
+		values map[string]interface{}
+		want   string
+	}{
+		{"defaults", map[string]interface{}{"name": "two-sum"}, "two-sum:2:false"},
+		{"supplied", map[string]interface{}{"name": "two-sum", "hints": float64(0), "strict": true}, "two-sum:0:true"},
+		{"nil falls back", map[string]interface{}{"name": "two-sum", "hints": nil}, "two-sum:2:false"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Render(content, schema, tt.values)
+			if err != nil {
+				t.Fatalf("Render: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Render = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRenderRejectsValues(t *testing.T) {
+	schema := VariableSchema{
+		"name":  {Type: VarTypeString, Required: true},
+		"code":  {Type: VarTypeString, Required: true},
+		"hints": {Type: VarTypeInteger},
+	}
+	_, err := Render(`{{.name}}`, schema, map[string]interface{}{"hints": 1.5, "extra": "x", "other": 1})
+
+	var tmplErr *TemplateError
+	if !errors.As(err, &tmplErr) {
+		t.Fatalf("Render error = %v, want a TemplateError", err)
+	}
+	if want := []string{"code", "name"}; !reflect.DeepEqual(tmplErr.Missing, want) {
+		t.Errorf("Missing = %v, want %v", tmplErr.Missing, want)
+	}
+	if want := []string{"extra", "other"}; !reflect.DeepEqual(tmplErr.Extra, want) {
+		t.Errorf("Extra = %v, want %v", tmplErr.Extra, want)
+	}
+	if _, ok := tmplErr.Invalid["hints"]; !ok {
+		t.Errorf("non-integer hints not reported as invalid")
+	}
+}
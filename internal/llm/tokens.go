@@ -0,0 +1,10 @@
+package llm
+
+import "unicode/utf8"
+
+// EstimateTokens approximates how many tokens text costs. Models tokenize
+// differently, so it uses the common rule of thumb of four characters per
+// token, which errs on the high side for English prose and code.
+func EstimateTokens(text string) int {
+	return (utf8.RuneCountInString(text) + 3) / 4
+}
@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+)
+
+// APIKey authenticates a machine client. Only the SHA-256 hash of the key is
+// stored; the key itself is shown once when it is issued.
+// @Description API key of a service client
+type APIKey struct {
+	ID         uuid.UUID                   `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Name       string                      `json:"name" gorm:"type:text;not null"`
+	Prefix     string                      `json:"prefix" gorm:"type:varchar(16);not null"` // First characters of the key, to recognise it
+	KeyHash    string                      `json:"-" gorm:"type:varchar(64);not null;uniqueIndex"`
+	Scopes     datatypes.JSONSlice[string] `json:"scopes" gorm:"type:jsonb;not null"`
+	CreatedBy  *uuid.UUID                  `json:"created_by,omitempty" gorm:"type:uuid"`
+	ExpiresAt  *time.Time                  `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time                  `json:"last_used_at,omitempty"`
+	RevokedAt  *time.Time                  `json:"revoked_at,omitempty"`
+	CreatedAt  time.Time                   `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the APIKey model
+func (APIKey) TableName() string {
+	return "api_keys"
+}
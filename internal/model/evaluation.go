@@ -7,21 +7,41 @@ import (
 	"gorm.io/datatypes"
 )
 
+type EvaluationStatus string
+
+const (
+	EvaluationStatusPending   EvaluationStatus = "pending" // Queued, or waiting for a retry
+	EvaluationStatusRunning   EvaluationStatus = "running"
+	EvaluationStatusSucceeded EvaluationStatus = "succeeded"
+	EvaluationStatusFailed    EvaluationStatus = "failed" // Every attempt failed
+)
+
 type Evaluation struct {
-	ID                  uuid.UUID      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	InterviewID         uuid.UUID      `json:"interview_id" gorm:"type:uuid;not null;uniqueIndex"`
-	ProblemSolvingScore int            `json:"problem_solving_score"`
-	CodeQualityScore    int            `json:"code_quality_score"`
-	CommunicationScore  int            `json:"communication_score"`
-	TechnicalScore      int            `json:"technical_score"`
-	OverallScore        int            `json:"overall_score"`
-	Strengths           datatypes.JSON `json:"strengths" gorm:"type:jsonb"`    // []string
-	Improvements        datatypes.JSON `json:"improvements" gorm:"type:jsonb"` // []string
-	DetailedFeedback    string         `json:"detailed_feedback"`
-	CreatedAt           time.Time      `json:"created_at" gorm:"autoCreateTime"`
+	ID                  uuid.UUID        `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	InterviewID         uuid.UUID        `json:"interview_id" gorm:"type:uuid;not null;uniqueIndex"`
+	Status              EvaluationStatus `json:"status" gorm:"type:varchar(20);default:'succeeded'"`
+	Error               string           `json:"error,omitempty" gorm:"type:text"` // Why the last attempt failed
+	Attempts            int              `json:"attempts"`
+	NextAttemptAt       *time.Time       `json:"next_attempt_at,omitempty"`             // Set while waiting for a retry
+	Partial             bool             `json:"partial" gorm:"not null;default:false"` // The interview was abandoned before it ended
+	ProblemSolvingScore int              `json:"problem_solving_score"`
+	CodeQualityScore    int              `json:"code_quality_score"`
+	CommunicationScore  int              `json:"communication_score"`
+	TechnicalScore      int              `json:"technical_score"`
+	OverallScore        int              `json:"overall_score"`
+	HintsUsed           int              `json:"hints_used"`
+	HintPenalty         int              `json:"hint_penalty"`                   // Taken off the problem solving and overall scores
+	Strengths           datatypes.JSON   `json:"strengths" gorm:"type:jsonb"`    // []string
+	Improvements        datatypes.JSON   `json:"improvements" gorm:"type:jsonb"` // []string
+	DetailedFeedback    string           `json:"detailed_feedback"`
+	Model               string           `json:"model,omitempty" gorm:"type:varchar(100)"` // LLM that produced the evaluation
+	PromptTemplateID    *uint            `json:"prompt_template_id"`                       // Evaluator template, nil for the built-in prompt
+	PromptVersion       string           `json:"prompt_version" gorm:"type:text"`
+	CreatedAt           time.Time        `json:"created_at" gorm:"autoCreateTime"`
+
+	TokenUsage `gorm:"embedded"` // Summed over every attempt
 }
 
 func (Evaluation) TableName() string {
 	return "evaluations"
 }
-
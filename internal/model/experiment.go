@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+type ExperimentStatus string
+
+const (
+	ExperimentStatusDraft   ExperimentStatus = "draft"
+	ExperimentStatusRunning ExperimentStatus = "running"
+	ExperimentStatusStopped ExperimentStatus = "stopped"
+)
+
+// Experiment splits new interviews between prompt template versions
+// @Description A/B experiment on versions of one prompt template name
+type Experiment struct {
+	ID         uint             `json:"id" gorm:"primaryKey"`
+	Name       string           `json:"name" gorm:"not null;type:text;uniqueIndex"`
+	PromptName string           `json:"prompt_name" gorm:"not null;type:text;index"`
+	Status     ExperimentStatus `json:"status" gorm:"type:varchar(20);not null;default:'draft'"`
+	StartedAt  *time.Time       `json:"started_at"`
+	StoppedAt  *time.Time       `json:"stopped_at"`
+	CreatedAt  time.Time        `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt  time.Time        `json:"updated_at" gorm:"autoUpdateTime"`
+
+	Arms []ExperimentArm `json:"arms" gorm:"foreignKey:ExperimentID"`
+}
+
+// TableName specifies the table name for the Experiment model
+func (Experiment) TableName() string {
+	return "experiments"
+}
+
+// ExperimentArm is one prompt template version under test and its traffic weight
+// @Description Arm of an A/B experiment
+type ExperimentArm struct {
+	ID           uint   `json:"id" gorm:"primaryKey"`
+	ExperimentID uint   `json:"experiment_id" gorm:"not null;index"`
+	Name         string `json:"name" gorm:"not null;type:text"`
+	TemplateID   uint   `json:"template_id" gorm:"not null"`
+	Version      string `json:"version" gorm:"not null;type:text"`
+	Weight       int    `json:"weight" gorm:"not null"`
+}
+
+// TableName specifies the table name for the ExperimentArm model
+func (ExperimentArm) TableName() string {
+	return "experiment_arms"
+}
+
+// ExperimentArmStats aggregates the outcome of the interviews assigned to an arm
+// @Description Per-arm experiment report row
+type ExperimentArmStats struct {
+	ArmID                  uint     `json:"arm_id"`
+	ArmName                string   `json:"arm_name"`
+	Version                string   `json:"version"`
+	Interviews             int64    `json:"interviews"`
+	CompletedInterviews    int64    `json:"completed_interviews"`
+	Evaluations            int64    `json:"evaluations"`
+	AvgOverallScore        *float64 `json:"avg_overall_score"`
+	AvgProblemSolvingScore *float64 `json:"avg_problem_solving_score"`
+	AvgCodeQualityScore    *float64 `json:"avg_code_quality_score"`
+	AvgCommunicationScore  *float64 `json:"avg_communication_score"`
+	AvgTechnicalScore      *float64 `json:"avg_technical_score"`
+	AvgMessages            *float64 `json:"avg_messages"`
+	AvgDurationSeconds     *float64 `json:"avg_duration_seconds"`
+}
@@ -11,19 +11,37 @@ type InterviewStatus string
 
 const (
 	InterviewStatusActive    InterviewStatus = "active"
+	InterviewStatusPaused    InterviewStatus = "paused"
 	InterviewStatusCompleted InterviewStatus = "completed"
 	InterviewStatusAbandoned InterviewStatus = "abandoned"
 )
 
 type Interview struct {
-	ID              uuid.UUID       `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	UserID          uuid.UUID       `json:"user_id" gorm:"type:uuid;not null;index"`
-	ProblemID       uuid.UUID       `json:"problem_id" gorm:"type:uuid;not null"`
-	ProblemSnapshot datatypes.JSON  `json:"problem_snapshot" gorm:"type:jsonb;not null"`
-	Status          InterviewStatus `json:"status" gorm:"type:varchar(20);default:'active';index"`
-	GeminiSessionID string          `json:"gemini_session_id" gorm:"type:varchar(255)"`
-	StartedAt       time.Time       `json:"started_at" gorm:"autoCreateTime"`
-	EndedAt         *time.Time      `json:"ended_at"`
+	ID               uuid.UUID                            `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	UserID           uuid.UUID                            `json:"user_id" gorm:"type:uuid;not null;index"`
+	ProblemID        uuid.UUID                            `json:"problem_id" gorm:"type:uuid;not null"`
+	ProblemSnapshot  datatypes.JSON                       `json:"problem_snapshot" gorm:"type:jsonb;not null"`
+	HiddenTestCases  datatypes.JSONSlice[ProblemTestCase] `json:"-" gorm:"type:jsonb"` // Run against submissions, kept out of the snapshot the candidate and models see
+	Status           InterviewStatus                      `json:"status" gorm:"type:varchar(20);default:'active';index"`
+	GeminiSessionID  string                               `json:"gemini_session_id" gorm:"type:varchar(255)"`
+	PromptTemplateID *uint                                `json:"prompt_template_id"` // Interviewer template, nil for the built-in prompt
+	PromptVersion    string                               `json:"prompt_version" gorm:"type:text"`
+	ExperimentID     *uint                                `json:"experiment_id,omitempty" gorm:"index"`
+	ExperimentArmID  *uint                                `json:"experiment_arm_id,omitempty" gorm:"index"`
+	DurationMinutes  int                                  `json:"duration_minutes"`                         // Time budget, 0 for untimed
+	ActiveSeconds    int64                                `json:"active_seconds" gorm:"not null;default:0"` // Active time accumulated before ResumedAt
+	HintsUsed        int                                  `json:"hints_used" gorm:"not null;default:0"`     // Also the level of the last hint given
+	ResumedAt        *time.Time                           `json:"resumed_at,omitempty"`                     // Start of the current active stretch, nil while paused or ended
+	PausedAt         *time.Time                           `json:"paused_at,omitempty"`                      // Start of the current pause
+	StartedAt        time.Time                            `json:"started_at" gorm:"autoCreateTime"`
+	EndedAt          *time.Time                           `json:"ended_at"`
+
+	// Running summary of the turns folded out of the chat context window
+	TranscriptSummary string                              `json:"transcript_summary,omitempty" gorm:"type:text"`
+	SummarizedThrough *uuid.UUID                          `json:"-" gorm:"type:uuid"`  // Last message folded into the summary
+	PreservedCode     datatypes.JSONSlice[CodeAttachment] `json:"-" gorm:"type:jsonb"` // Code attached in folded turns, kept verbatim
+	SummaryModel      string                              `json:"-" gorm:"type:varchar(100)"`
+	SummaryUsage      TokenUsage                          `json:"summary_usage" gorm:"embedded;embeddedPrefix:summary_"` // Summed over every refresh
 
 	Messages    []Message    `json:"messages" gorm:"foreignKey:InterviewID"`
 	Submissions []Submission `json:"submissions" gorm:"foreignKey:InterviewID"`
@@ -33,3 +51,24 @@ type Interview struct {
 func (Interview) TableName() string {
 	return "interviews"
 }
+
+// CodeAttachment is code the candidate attached to a chat message.
+type CodeAttachment struct {
+	Language string `json:"language"`
+	Code     string `json:"code"`
+}
+
+// InterviewSummary is the list projection of an interview: no messages or
+// submissions, plus the outcome of its evaluation.
+type InterviewSummary struct {
+	ID               uuid.UUID       `json:"id"`
+	UserID           uuid.UUID       `json:"user_id"`
+	ProblemID        uuid.UUID       `json:"problem_id"`
+	Status           InterviewStatus `json:"status"`
+	PromptVersion    string          `json:"prompt_version"`
+	DurationMinutes  int             `json:"duration_minutes"`
+	StartedAt        time.Time       `json:"started_at"`
+	EndedAt          *time.Time      `json:"ended_at"`
+	EvaluationStatus *string         `json:"evaluation_status"` // Nil until the interview is ended
+	OverallScore     *int            `json:"overall_score"`     // Nil unless the evaluation succeeded
+}
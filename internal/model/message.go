@@ -11,18 +11,51 @@ type MessageRole string
 const (
 	MessageRoleUser      MessageRole = "user"
 	MessageRoleAssistant MessageRole = "assistant"
-	MessageRoleSystem    MessageRole = "system"
+	MessageRoleSystem    MessageRole = "system" // Instruction the interviewer ran with, kept for auditing
 )
 
+// ConversationRoles are the roles of messages exchanged with the candidate.
+var ConversationRoles = []MessageRole{MessageRoleUser, MessageRoleAssistant}
+
+// HintLevel is how much of the solution a hint gives away. Hints are given
+// in increasing levels, one per level.
+type HintLevel int
+
+const (
+	HintLevelNone       HintLevel = iota // Not a hint
+	HintLevelNudge                       // Points the candidate in a direction
+	HintLevelApproach                    // Outlines the approach to take
+	HintLevelPseudoCode                  // Walks through the solution in pseudo-code
+
+	MaxHintLevel = HintLevelPseudoCode
+)
+
+func (l HintLevel) String() string {
+	switch l {
+	case HintLevelNudge:
+		return "nudge"
+	case HintLevelApproach:
+		return "approach"
+	case HintLevelPseudoCode:
+		return "pseudo-code"
+	default:
+		return "none"
+	}
+}
+
 type Message struct {
 	ID          uuid.UUID   `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
 	InterviewID uuid.UUID   `json:"interview_id" gorm:"type:uuid;not null;index"`
 	Role        MessageRole `json:"role" gorm:"type:varchar(20);not null"`
 	Content     string      `json:"content" gorm:"not null"`
+	Model       string      `json:"model,omitempty" gorm:"type:varchar(100)"`          // LLM that produced an assistant message
+	Truncated   bool        `json:"truncated,omitempty" gorm:"not null;default:false"` // Stream was cut before the reply completed
+	HintLevel   HintLevel   `json:"hint_level,omitempty" gorm:"not null;default:0"`    // Set on hint requests and the hints given
 	CreatedAt   time.Time   `json:"created_at" gorm:"autoCreateTime"`
+
+	TokenUsage `gorm:"embedded"` // Set on assistant messages
 }
 
 func (Message) TableName() string {
 	return "messages"
 }
-
@@ -0,0 +1,85 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+type ProblemDifficulty string
+
+const (
+	ProblemDifficultyEasy   ProblemDifficulty = "easy"
+	ProblemDifficultyMedium ProblemDifficulty = "medium"
+	ProblemDifficultyHard   ProblemDifficulty = "hard"
+)
+
+// ProblemExample is a worked example shown to the candidate.
+type ProblemExample struct {
+	Input       string `json:"input" yaml:"input"`
+	Output      string `json:"output" yaml:"output"`
+	Explanation string `json:"explanation,omitempty" yaml:"explanation,omitempty"`
+}
+
+// ProblemTestCase is a test case submissions are run against. Hidden test
+// cases are never shown to the candidate.
+type ProblemTestCase struct {
+	Input    string `json:"input" yaml:"input"`
+	Expected string `json:"expected" yaml:"expected"`
+}
+
+// Problem is a coding problem of the problem bank. Interviews keep a
+// snapshot of it, so editing a problem never changes running interviews.
+// @Description Coding problem of the problem bank
+type Problem struct {
+	ID                uuid.UUID                            `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	Slug              string                               `json:"slug" gorm:"type:varchar(100);not null"` // Unique among problems that are not deleted
+	Title             string                               `json:"title" gorm:"type:text;not null"`
+	Statement         string                               `json:"statement" gorm:"type:text;not null"`
+	Difficulty        ProblemDifficulty                    `json:"difficulty" gorm:"type:varchar(20);not null;index"`
+	Tags              datatypes.JSONSlice[string]          `json:"tags" gorm:"type:jsonb"`
+	Constraints       datatypes.JSONSlice[string]          `json:"constraints" gorm:"type:jsonb"`
+	Examples          datatypes.JSONSlice[ProblemExample]  `json:"examples" gorm:"type:jsonb"`
+	HiddenTestCases   datatypes.JSONSlice[ProblemTestCase] `json:"hidden_test_cases,omitempty" gorm:"type:jsonb"`
+	ReferenceSolution string                               `json:"reference_solution,omitempty" gorm:"type:text"`
+	ReferenceLanguage string                               `json:"reference_language,omitempty" gorm:"type:varchar(50)"`
+	Editorial         string                               `json:"editorial,omitempty" gorm:"type:text"`
+	CreatedAt         time.Time                            `json:"created_at" gorm:"autoCreateTime"`
+	UpdatedAt         time.Time                            `json:"updated_at" gorm:"autoUpdateTime"`
+	DeletedAt         gorm.DeletedAt                       `json:"-" gorm:"index"`
+}
+
+// TableName specifies the table name for the Problem model
+func (Problem) TableName() string {
+	return "problems"
+}
+
+// Public returns a copy of the problem without what candidates must not see:
+// hidden test cases, the reference solution and the editorial.
+func (p Problem) Public() Problem {
+	p.HiddenTestCases = nil
+	p.ReferenceSolution = ""
+	p.ReferenceLanguage = ""
+	p.Editorial = ""
+	return p
+}
+
+// Snapshot captures what the interviewer presents of the problem. It is
+// returned to the candidate and rendered into prompts, so hidden test cases
+// are copied onto the interview separately.
+func (p *Problem) Snapshot() (datatypes.JSON, error) {
+	raw, err := json.Marshal(struct {
+		ID          uuid.UUID         `json:"id"`
+		Slug        string            `json:"slug"`
+		Title       string            `json:"title"`
+		Statement   string            `json:"statement"`
+		Difficulty  ProblemDifficulty `json:"difficulty"`
+		Tags        []string          `json:"tags,omitempty"`
+		Constraints []string          `json:"constraints,omitempty"`
+		Examples    []ProblemExample  `json:"examples,omitempty"`
+	}{p.ID, p.Slug, p.Title, p.Statement, p.Difficulty, p.Tags, p.Constraints, p.Examples})
+	return datatypes.JSON(raw), err
+}
@@ -0,0 +1,53 @@
+package model
+
+import "time"
+
+// Release channels a prompt template version can be promoted to.
+const (
+	PromptChannelProduction = "production"
+	PromptChannelStaging    = "staging"
+	PromptChannelCanary     = "canary"
+)
+
+// PromptChannelActions recorded in the release history.
+const (
+	PromptChannelActionPromote  = "promote"
+	PromptChannelActionRollback = "rollback"
+)
+
+// PromptChannel points a release channel of a template name at one version
+// @Description Release channel of a prompt template name
+type PromptChannel struct {
+	ID         uint      `json:"id" gorm:"primaryKey"`
+	Name       string    `json:"name" gorm:"not null;type:text;uniqueIndex:idx_prompt_channels_name_channel"`
+	Channel    string    `json:"channel" gorm:"not null;type:varchar(20);uniqueIndex:idx_prompt_channels_name_channel"`
+	TemplateID uint      `json:"template_id" gorm:"not null"`
+	Version    string    `json:"version" gorm:"not null;type:text"`
+	UpdatedBy  string    `json:"updated_by" gorm:"type:text"`
+	UpdatedAt  time.Time `json:"updated_at" gorm:"autoUpdateTime"`
+}
+
+// TableName specifies the table name for the PromptChannel model
+func (PromptChannel) TableName() string {
+	return "prompt_channels"
+}
+
+// PromptChannelHistory records every promotion and rollback of a channel
+// @Description Release history entry of a prompt channel
+type PromptChannelHistory struct {
+	ID             uint      `json:"id" gorm:"primaryKey"`
+	Name           string    `json:"name" gorm:"not null;type:text;index:idx_prompt_channel_history_name_channel"`
+	Channel        string    `json:"channel" gorm:"not null;type:varchar(20);index:idx_prompt_channel_history_name_channel"`
+	Action         string    `json:"action" gorm:"not null;type:varchar(20)"`
+	FromTemplateID *uint     `json:"from_template_id"`
+	FromVersion    string    `json:"from_version" gorm:"type:text"`
+	ToTemplateID   uint      `json:"to_template_id" gorm:"not null"`
+	ToVersion      string    `json:"to_version" gorm:"not null;type:text"`
+	PerformedBy    string    `json:"performed_by" gorm:"type:text"`
+	CreatedAt      time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+// TableName specifies the table name for the PromptChannelHistory model
+func (PromptChannelHistory) TableName() string {
+	return "prompt_channel_history"
+}
@@ -8,17 +8,23 @@ import (
 )
 
 type Submission struct {
-	ID          uuid.UUID      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
-	InterviewID uuid.UUID      `json:"interview_id" gorm:"type:uuid;not null;index"`
-	Code        string         `json:"code" gorm:"not null"`
-	Language    string         `json:"language" gorm:"type:varchar(20);not null"`
-	AIFeedback  string         `json:"ai_feedback"`
-	IsCorrect   *bool          `json:"is_correct"`
-	TestResults datatypes.JSON `json:"test_results" gorm:"type:jsonb"` // Store simulated test results
-	SubmittedAt time.Time      `json:"submitted_at" gorm:"autoCreateTime"`
+	ID               uuid.UUID      `json:"id" gorm:"type:uuid;default:gen_random_uuid();primaryKey"`
+	InterviewID      uuid.UUID      `json:"interview_id" gorm:"type:uuid;not null;index"`
+	Code             string         `json:"code" gorm:"not null"`
+	Language         string         `json:"language" gorm:"type:varchar(20);not null"`
+	AIFeedback       string         `json:"ai_feedback"`
+	IsCorrect        *bool          `json:"is_correct"`
+	Complexity       string         `json:"complexity,omitempty"`
+	Suggestions      datatypes.JSON `json:"suggestions,omitempty" gorm:"type:jsonb"` // []string
+	TestResults      datatypes.JSON `json:"test_results" gorm:"type:jsonb"`          // Executed and simulated test results
+	PromptTemplateID *uint          `json:"prompt_template_id"`                      // Reviewer template, nil for the built-in prompt
+	PromptVersion    string         `json:"prompt_version" gorm:"type:text"`
+	Model            string         `json:"model,omitempty" gorm:"type:varchar(100)"` // LLM that reviewed the code
+	SubmittedAt      time.Time      `json:"submitted_at" gorm:"autoCreateTime"`
+
+	TokenUsage `gorm:"embedded"`
 }
 
 func (Submission) TableName() string {
 	return "submissions"
 }
-
@@ -0,0 +1,34 @@
+package model
+
+// TokenUsage is what one LLM call cost. It is embedded in every record an
+// LLM produced.
+type TokenUsage struct {
+	PromptTokens     int     `json:"prompt_tokens" gorm:"not null;default:0"`
+	CompletionTokens int     `json:"completion_tokens" gorm:"not null;default:0"`
+	CostUSD          float64 `json:"cost_usd" gorm:"type:numeric(12,6);not null;default:0"` // From the configured price table
+}
+
+// Add accumulates other, e.g. across the attempts of a retried job.
+func (u *TokenUsage) Add(other TokenUsage) {
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+	u.CostUSD += other.CostUSD
+}
+
+// UsageTotals sums the LLM calls of an interview or a report period.
+// @Description Token and cost totals
+type UsageTotals struct {
+	Calls            int64   `json:"calls"`
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	TotalTokens      int64   `json:"total_tokens"`
+	CostUSD          float64 `json:"cost_usd"`
+}
+
+// DailyUsage is one row of the usage report.
+// @Description LLM usage of one model on one UTC day
+type DailyUsage struct {
+	Day   string `json:"day"` // YYYY-MM-DD
+	Model string `json:"model"`
+	UsageTotals
+}
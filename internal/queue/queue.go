@@ -0,0 +1,175 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// Job is one unit of work on a queue.
+type Job struct {
+	ID      string          `json:"id"`
+	Attempt int             `json:"attempt"` // 1 on the first run
+	Payload json.RawMessage `json:"payload,omitempty"`
+
+	raw string // Encoded form as stored in Redis, needed to acknowledge it
+}
+
+// Queue is a durable FIFO of jobs with support for delayed delivery.
+//
+// Every named queue uses a ready list and a sorted set of delayed jobs scored
+// by the time they become ready. Each process is a consumer with its own list
+// of jobs handed out but not yet acknowledged, and a heartbeat key; the jobs
+// of a consumer whose heartbeat expired are reclaimed by the others.
+type Queue interface {
+	// Enqueue makes the job ready immediately.
+	Enqueue(ctx context.Context, name string, job *Job) error
+	// EnqueueAt makes the job ready once at has passed.
+	EnqueueAt(ctx context.Context, name string, job *Job, at time.Time) error
+	// Dequeue blocks up to timeout for a ready job. It returns nil, nil when
+	// nothing arrived in time. The job must be acknowledged with Ack.
+	Dequeue(ctx context.Context, name string, timeout time.Duration) (*Job, error)
+	// Ack removes a finished job from this consumer's in-flight list.
+	Ack(ctx context.Context, name string, job *Job) error
+	// PromoteDue moves delayed jobs whose time has come to the ready list.
+	PromoteDue(ctx context.Context, name string) (int, error)
+	// Heartbeat registers this consumer and keeps it alive for ttl. Call it
+	// before the first Dequeue and well within ttl afterwards.
+	Heartbeat(ctx context.Context, name string, ttl time.Duration) error
+	// Reclaim puts the in-flight jobs of consumers whose heartbeat expired,
+	// crashed or stopped processes, back on the ready list.
+	Reclaim(ctx context.Context, name string) (int, error)
+}
+
+type redisQueue struct {
+	rdb      *redis.Client
+	consumer string // Unique to this process
+}
+
+func NewRedisQueue(rdb *redis.Client) Queue {
+	host, _ := os.Hostname()
+	return &redisQueue{
+		rdb:      rdb,
+		consumer: fmt.Sprintf("%s-%d-%s", host, os.Getpid(), uuid.NewString()[:8]),
+	}
+}
+
+func readyKey(name string) string     { return "minos:queue:" + name }
+func delayedKey(name string) string   { return "minos:queue:" + name + ":delayed" }
+func consumersKey(name string) string { return "minos:queue:" + name + ":consumers" }
+func processingKey(name, consumer string) string {
+	return "minos:queue:" + name + ":processing:" + consumer
+}
+func aliveKey(name, consumer string) string {
+	return "minos:queue:" + name + ":alive:" + consumer
+}
+
+// promoteScript atomically moves due members of the delayed set to the ready list.
+var promoteScript = redis.NewScript(`
+local items = redis.call('ZRANGEBYSCORE', KEYS[1], '-inf', ARGV[1], 'LIMIT', 0, 100)
+for _, item in ipairs(items) do
+	redis.call('ZREM', KEYS[1], item)
+	redis.call('LPUSH', KEYS[2], item)
+end
+return #items
+`)
+
+// reclaimScript moves the in-flight jobs of a consumer back to the ready list
+// and forgets it, unless its heartbeat came back meanwhile.
+var reclaimScript = redis.NewScript(`
+if redis.call('EXISTS', KEYS[1]) == 1 then
+	return 0
+end
+local moved = 0
+while redis.call('LMOVE', KEYS[2], KEYS[3], 'LEFT', 'RIGHT') do
+	moved = moved + 1
+end
+redis.call('SREM', KEYS[4], ARGV[1])
+return moved
+`)
+
+func (q *redisQueue) Enqueue(ctx context.Context, name string, job *Job) error {
+	raw, err := encode(job)
+	if err != nil {
+		return err
+	}
+	return q.rdb.LPush(ctx, readyKey(name), raw).Err()
+}
+
+func (q *redisQueue) EnqueueAt(ctx context.Context, name string, job *Job, at time.Time) error {
+	raw, err := encode(job)
+	if err != nil {
+		return err
+	}
+	return q.rdb.ZAdd(ctx, delayedKey(name), redis.Z{Score: float64(at.UnixMilli()), Member: raw}).Err()
+}
+
+func (q *redisQueue) Dequeue(ctx context.Context, name string, timeout time.Duration) (*Job, error) {
+	raw, err := q.rdb.BLMove(ctx, readyKey(name), processingKey(name, q.consumer), "RIGHT", "LEFT", timeout).Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	var job Job
+	if err := json.Unmarshal([]byte(raw), &job); err != nil {
+		// Drop undecodable entries so they cannot block the queue forever
+		q.rdb.LRem(ctx, processingKey(name, q.consumer), 1, raw)
+		return nil, fmt.Errorf("invalid job on queue %s: %w", name, err)
+	}
+	job.raw = raw
+	return &job, nil
+}
+
+func (q *redisQueue) Ack(ctx context.Context, name string, job *Job) error {
+	return q.rdb.LRem(ctx, processingKey(name, q.consumer), 1, job.raw).Err()
+}
+
+func (q *redisQueue) PromoteDue(ctx context.Context, name string) (int, error) {
+	now := strconv.FormatInt(time.Now().UnixMilli(), 10)
+	return promoteScript.Run(ctx, q.rdb, []string{delayedKey(name), readyKey(name)}, now).Int()
+}
+
+func (q *redisQueue) Heartbeat(ctx context.Context, name string, ttl time.Duration) error {
+	_, err := q.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.SAdd(ctx, consumersKey(name), q.consumer)
+		pipe.Set(ctx, aliveKey(name, q.consumer), "1", ttl)
+		return nil
+	})
+	return err
+}
+
+func (q *redisQueue) Reclaim(ctx context.Context, name string) (int, error) {
+	consumers, err := q.rdb.SMembers(ctx, consumersKey(name)).Result()
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, consumer := range consumers {
+		keys := []string{aliveKey(name, consumer), processingKey(name, consumer), readyKey(name), consumersKey(name)}
+		n, err := reclaimScript.Run(ctx, q.rdb, keys, consumer).Int()
+		if err != nil {
+			return moved, err
+		}
+		moved += n
+	}
+	return moved, nil
+}
+
+func encode(job *Job) (string, error) {
+	raw, err := json.Marshal(job)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode job: %w", err)
+	}
+	return string(raw), nil
+}
@@ -0,0 +1,74 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"minos/config"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// TokenBudget caps the LLM tokens spent on behalf of a user per UTC day,
+// based on the usage the model reports for every call.
+type TokenBudget interface {
+	// Check returns a *LimitError once the user's budget for today is spent.
+	Check(ctx context.Context, userID uuid.UUID) error
+	// Consume adds tokens to the user's spending for today.
+	Consume(ctx context.Context, userID uuid.UUID, tokens int) error
+}
+
+type redisTokenBudget struct {
+	rdb   *redis.Client
+	limit int64
+}
+
+func NewTokenBudget(rdb *redis.Client, cfg *config.Config) TokenBudget {
+	limit := cfg.RateLimit.DailyTokenBudget
+	if !cfg.RateLimit.Enabled {
+		limit = 0
+	}
+	return &redisTokenBudget{rdb: rdb, limit: limit}
+}
+
+// budgetKey is per day so yesterday's spending simply expires.
+func budgetKey(userID uuid.UUID, day time.Time) string {
+	return "minos:budget:" + userID.String() + ":" + day.Format("2006-01-02")
+}
+
+func (b *redisTokenBudget) Check(ctx context.Context, userID uuid.UUID) error {
+	if b.limit <= 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	spent, err := b.rdb.Get(ctx, budgetKey(userID, now)).Int64()
+	if err != nil && !errors.Is(err, redis.Nil) {
+		return err
+	}
+	if spent < b.limit {
+		return nil
+	}
+	return &LimitError{
+		Reason:     fmt.Sprintf("daily budget of %d tokens used up", b.limit),
+		RetryAfter: nextDay(now).Sub(now),
+	}
+}
+
+func (b *redisTokenBudget) Consume(ctx context.Context, userID uuid.UUID, tokens int) error {
+	if b.limit <= 0 || tokens <= 0 {
+		return nil
+	}
+	now := time.Now().UTC()
+	key := budgetKey(userID, now)
+	pipe := b.rdb.TxPipeline()
+	pipe.IncrBy(ctx, key, int64(tokens))
+	pipe.ExpireAt(ctx, key, nextDay(now).Add(time.Hour))
+	_, err := pipe.Exec(ctx)
+	return err
+}
+
+func nextDay(now time.Time) time.Time {
+	return time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, time.UTC)
+}
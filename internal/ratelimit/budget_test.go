@@ -0,0 +1,75 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"minos/config"
+
+	"github.com/google/uuid"
+)
+
+func TestNextDay(t *testing.T) {
+	tests := []struct {
+		now  time.Time
+		want time.Time
+	}{
+		{time.Date(2025, 3, 14, 15, 9, 26, 0, time.UTC), time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 3, 14, 0, 0, 0, 0, time.UTC), time.Date(2025, 3, 15, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2025, 12, 31, 23, 59, 59, 0, time.UTC), time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)},
+		{time.Date(2024, 2, 28, 12, 0, 0, 0, time.UTC), time.Date(2024, 2, 29, 0, 0, 0, 0, time.UTC)},
+	}
+	for _, tt := range tests {
+		if got := nextDay(tt.now); !got.Equal(tt.want) {
+			t.Errorf("nextDay(%s) = %s, want %s", tt.now, got, tt.want)
+		}
+	}
+}
+
+func TestBudgetKeyIsPerDay(t *testing.T) {
+	userID := uuid.MustParse("5d7c1e0a-3f8b-4c55-9a3e-0f6f0e2b7a11")
+	day := time.Date(2025, 3, 14, 23, 59, 0, 0, time.UTC)
+
+	if got, want := budgetKey(userID, day), "minos:budget:5d7c1e0a-3f8b-4c55-9a3e-0f6f0e2b7a11:2025-03-14"; got != want {
+		t.Errorf("budgetKey = %q, want %q", got, want)
+	}
+	if budgetKey(userID, day) == budgetKey(userID, nextDay(day)) {
+		t.Error("budgetKey is the same on consecutive days")
+	}
+}
+
+func TestDisabledBudgetNeverLimits(t *testing.T) {
+	// Without a limit the budget must not touch Redis, so no client is needed
+	disabled := []*config.Config{
+		{RateLimit: config.RateLimitConfig{Enabled: false, DailyTokenBudget: 100}},
+		{RateLimit: config.RateLimitConfig{Enabled: true, DailyTokenBudget: 0}},
+	}
+	for _, cfg := range disabled {
+		budget := NewTokenBudget(nil, cfg)
+		if err := budget.Check(context.Background(), uuid.New()); err != nil {
+			t.Errorf("Check = %v, want nil", err)
+		}
+		if err := budget.Consume(context.Background(), uuid.New(), 1000); err != nil {
+			t.Errorf("Consume = %v, want nil", err)
+		}
+	}
+}
+
+func TestLimitError(t *testing.T) {
+	err := &LimitError{Reason: "daily budget of 100 tokens used up", RetryAfter: 1500 * time.Millisecond}
+	if !errors.Is(err, ErrLimitExceeded) {
+		t.Error("LimitError does not wrap ErrLimitExceeded")
+	}
+	if got := err.RetryAfterSeconds(); got != 2 {
+		t.Errorf("RetryAfterSeconds = %d, want 2", got)
+	}
+
+	for _, retryAfter := range []time.Duration{0, -time.Second, time.Millisecond} {
+		err := &LimitError{RetryAfter: retryAfter}
+		if got := err.RetryAfterSeconds(); got != 1 {
+			t.Errorf("RetryAfterSeconds(%s) = %d, want 1", retryAfter, got)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+)
+
+// ErrLimitExceeded is wrapped by every LimitError.
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// LimitError reports a rejected request and when it may be retried.
+type LimitError struct {
+	Reason     string
+	RetryAfter time.Duration
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s, retry in %s", e.Reason, e.RetryAfter.Round(time.Second))
+}
+
+func (e *LimitError) Unwrap() error { return ErrLimitExceeded }
+
+// RetryAfterSeconds is the value of the Retry-After header, at least one.
+func (e *LimitError) RetryAfterSeconds() int {
+	seconds := int((e.RetryAfter + time.Second - 1) / time.Second)
+	if seconds < 1 {
+		return 1
+	}
+	return seconds
+}
+
+// Limiter counts requests in a sliding window.
+type Limiter interface {
+	// Allow records a request for subject unless limit requests were already
+	// made within window, in which case it returns a *LimitError.
+	Allow(ctx context.Context, subject string, limit int, window time.Duration) error
+}
+
+type redisLimiter struct {
+	rdb *redis.Client
+}
+
+func NewRedisLimiter(rdb *redis.Client) Limiter {
+	return &redisLimiter{rdb: rdb}
+}
+
+func limiterKey(subject string) string { return "minos:ratelimit:" + subject }
+
+// slidingWindowScript keeps one sorted set member per request scored by its
+// time. It drops members older than the window, then either records the
+// request or returns how long until the oldest one leaves the window.
+var slidingWindowScript = redis.NewScript(`
+local now = tonumber(ARGV[1])
+local window = tonumber(ARGV[2])
+local limit = tonumber(ARGV[3])
+redis.call('ZREMRANGEBYSCORE', KEYS[1], '-inf', now - window)
+if redis.call('ZCARD', KEYS[1]) < limit then
+	redis.call('ZADD', KEYS[1], now, ARGV[4])
+	redis.call('PEXPIRE', KEYS[1], window)
+	return 0
+end
+local oldest = redis.call('ZRANGE', KEYS[1], 0, 0, 'WITHSCORES')
+return tonumber(oldest[2]) + window - now
+`)
+
+func (l *redisLimiter) Allow(ctx context.Context, subject string, limit int, window time.Duration) error {
+	if limit <= 0 {
+		return nil
+	}
+	now := time.Now().UnixMilli()
+	// The member only has to be unique; the score carries the time
+	member := strconv.FormatInt(now, 10) + "-" + uuid.NewString()
+	wait, err := slidingWindowScript.Run(ctx, l.rdb, []string{limiterKey(subject)}, now, window.Milliseconds(), limit, member).Int64()
+	if err != nil {
+		return err
+	}
+	if wait > 0 {
+		return &LimitError{
+			Reason:     fmt.Sprintf("more than %d requests in %s", limit, window),
+			RetryAfter: time.Duration(wait) * time.Millisecond,
+		}
+	}
+	return nil
+}
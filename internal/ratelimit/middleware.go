@@ -0,0 +1,67 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"minos/config"
+	"minos/internal/auth"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// Middleware limits requests per API key or, for users, per user id. Callers
+// without either, such as every request while auth is disabled, are limited
+// per client IP with the user limit.
+func Middleware(limiter Limiter, cfg *config.Config) gin.HandlerFunc {
+	return func(ctx *gin.Context) {
+		if Abort(ctx, Allow(ctx.Request.Context(), limiter, cfg, auth.FromContext(ctx), ctx.ClientIP())) {
+			return
+		}
+		ctx.Next()
+	}
+}
+
+// Allow counts one request of principal, or of clientIP when there is none,
+// the way Middleware does, for requests that do not go through it such as
+// messages on a WebSocket. It returns a *LimitError once the limit is spent.
+func Allow(ctx context.Context, limiter Limiter, cfg *config.Config, principal *auth.Principal, clientIP string) error {
+	if !cfg.RateLimit.Enabled {
+		return nil
+	}
+
+	subject, limit := "ip:"+clientIP, cfg.RateLimit.UserRequests
+	if principal != nil {
+		switch {
+		case principal.IsService():
+			subject, limit = "key:"+principal.APIKeyID.String(), cfg.RateLimit.KeyRequests
+		case principal.UserID != uuid.Nil:
+			subject = "user:" + principal.UserID.String()
+		}
+	}
+
+	window := time.Duration(cfg.RateLimit.WindowSeconds) * time.Second
+	err := limiter.Allow(ctx, subject, limit, window)
+	if err != nil && !errors.Is(err, ErrLimitExceeded) {
+		// Fail open: Redis trouble should not take the API down
+		log.Warn().Err(err).Str("subject", subject).Msg("Rate limiter unavailable")
+		return nil
+	}
+	return err
+}
+
+// Abort answers 429 with a Retry-After header when err is a *LimitError and
+// reports whether it did.
+func Abort(ctx *gin.Context, err error) bool {
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) {
+		return false
+	}
+	ctx.Header("Retry-After", strconv.Itoa(limitErr.RetryAfterSeconds()))
+	ctx.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": limitErr.Error()})
+	return true
+}
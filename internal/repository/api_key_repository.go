@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"minos/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	CreateAPIKey(key *model.APIKey) error
+	FindAPIKeys() ([]model.APIKey, error)
+	FindAPIKeyByID(id uuid.UUID) (*model.APIKey, error)
+	FindAPIKeyByHash(hash string) (*model.APIKey, error)
+	// RevokeAPIKey marks the key revoked and reports whether it was active.
+	RevokeAPIKey(id uuid.UUID, at time.Time) (bool, error)
+	// TouchAPIKey records a use of the key unless one was recorded after since.
+	TouchAPIKey(id uuid.UUID, at time.Time, since time.Time) error
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db: db}
+}
+
+func (r *apiKeyRepository) CreateAPIKey(key *model.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindAPIKeys() ([]model.APIKey, error) {
+	var keys []model.APIKey
+	err := r.db.Order("created_at DESC").Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) FindAPIKeyByID(id uuid.UUID) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.First(&key, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) FindAPIKeyByHash(hash string) (*model.APIKey, error) {
+	var key model.APIKey
+	err := r.db.Where("key_hash = ?", hash).First(&key).Error
+	if err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+func (r *apiKeyRepository) RevokeAPIKey(id uuid.UUID, at time.Time) (bool, error) {
+	result := r.db.Model(&model.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Update("revoked_at", at)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *apiKeyRepository) TouchAPIKey(id uuid.UUID, at time.Time, since time.Time) error {
+	return r.db.Model(&model.APIKey{}).
+		Where("id = ? AND (last_used_at IS NULL OR last_used_at < ?)", id, since).
+		Update("last_used_at", at).Error
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+	"minos/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// BundleChannelMove points a release channel at a template version.
+type BundleChannelMove struct {
+	Name    string
+	Channel string
+	Version string
+}
+
+// BundleChanges are the writes of a bundle import. Channels are moved after
+// the templates are written, so they may point at versions created alongside.
+type BundleChanges struct {
+	CreatePrompts  []*model.PromptTemplate
+	UpdatePrompts  []*model.PromptTemplate
+	MoveChannels   []BundleChannelMove
+	CreateProblems []*model.Problem
+	UpdateProblems []*model.Problem
+}
+
+// Empty reports whether there is nothing to write.
+func (c *BundleChanges) Empty() bool {
+	return len(c.CreatePrompts) == 0 && len(c.UpdatePrompts) == 0 && len(c.MoveChannels) == 0 &&
+		len(c.CreateProblems) == 0 && len(c.UpdateProblems) == 0
+}
+
+type BundleRepository interface {
+	// ApplyBundle writes every change in one transaction; nothing is written
+	// when any of them fails. Channel moves are recorded in the release
+	// history as promotions by actor.
+	ApplyBundle(changes *BundleChanges, actor string) error
+}
+
+type bundleRepository struct {
+	db *gorm.DB
+}
+
+func NewBundleRepository(db *gorm.DB) BundleRepository {
+	return &bundleRepository{db: db}
+}
+
+func (r *bundleRepository) ApplyBundle(changes *BundleChanges, actor string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, template := range changes.CreatePrompts {
+			if err := tx.Create(template).Error; err != nil {
+				return fmt.Errorf("failed to create prompt '%s' version '%s': %w", template.Name, template.Version, err)
+			}
+		}
+		for _, template := range changes.UpdatePrompts {
+			if err := tx.Save(template).Error; err != nil {
+				return fmt.Errorf("failed to update prompt '%s' version '%s': %w", template.Name, template.Version, err)
+			}
+		}
+
+		for _, move := range changes.MoveChannels {
+			var target model.PromptTemplate
+			if err := tx.Where("name = ? AND version = ?", move.Name, move.Version).First(&target).Error; err != nil {
+				if errors.Is(err, gorm.ErrRecordNotFound) {
+					return fmt.Errorf("prompt '%s' version '%s' of channel '%s' does not exist", move.Name, move.Version, move.Channel)
+				}
+				return err
+			}
+			if _, err := movePromptChannel(tx, &target, move.Channel, model.PromptChannelActionPromote, actor); err != nil {
+				return fmt.Errorf("failed to move channel '%s' of prompt '%s': %w", move.Channel, move.Name, err)
+			}
+		}
+
+		for _, problem := range changes.CreateProblems {
+			if err := tx.Create(problem).Error; err != nil {
+				return fmt.Errorf("failed to create problem '%s': %w", problem.Slug, err)
+			}
+		}
+		for _, problem := range changes.UpdateProblems {
+			if err := tx.Save(problem).Error; err != nil {
+				return fmt.Errorf("failed to update problem '%s': %w", problem.Slug, err)
+			}
+		}
+		return nil
+	})
+}
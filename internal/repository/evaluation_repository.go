@@ -9,7 +9,9 @@ import (
 
 type EvaluationRepository interface {
 	CreateEvaluation(evaluation *model.Evaluation) error
+	FindEvaluationByID(id uuid.UUID) (*model.Evaluation, error)
 	FindEvaluationByInterviewID(interviewID uuid.UUID) (*model.Evaluation, error)
+	UpdateEvaluation(evaluation *model.Evaluation) error
 }
 
 type evaluationRepository struct {
@@ -24,6 +26,15 @@ func (r *evaluationRepository) CreateEvaluation(evaluation *model.Evaluation) er
 	return r.db.Create(evaluation).Error
 }
 
+func (r *evaluationRepository) FindEvaluationByID(id uuid.UUID) (*model.Evaluation, error) {
+	var evaluation model.Evaluation
+	err := r.db.First(&evaluation, "id = ?", id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &evaluation, nil
+}
+
 func (r *evaluationRepository) FindEvaluationByInterviewID(interviewID uuid.UUID) (*model.Evaluation, error) {
 	var evaluation model.Evaluation
 	err := r.db.Where("interview_id = ?", interviewID).First(&evaluation).Error
@@ -33,3 +44,6 @@ func (r *evaluationRepository) FindEvaluationByInterviewID(interviewID uuid.UUID
 	return &evaluation, nil
 }
 
+func (r *evaluationRepository) UpdateEvaluation(evaluation *model.Evaluation) error {
+	return r.db.Save(evaluation).Error
+}
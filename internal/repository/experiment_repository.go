@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"minos/internal/model"
+
+	"gorm.io/gorm"
+)
+
+type ExperimentRepository interface {
+	CreateExperiment(experiment *model.Experiment) error
+	FindAllExperiments(promptName string) ([]model.Experiment, error)
+	FindExperimentByID(id uint) (*model.Experiment, error)
+	FindRunningExperiment(promptName string) (*model.Experiment, error)
+	UpdateExperiment(experiment *model.Experiment) error
+	FindArmStats(experimentID uint) ([]model.ExperimentArmStats, error)
+}
+
+type experimentRepository struct {
+	db *gorm.DB
+}
+
+func NewExperimentRepository(db *gorm.DB) ExperimentRepository {
+	return &experimentRepository{db: db}
+}
+
+// CreateExperiment inserts the experiment together with its arms.
+func (r *experimentRepository) CreateExperiment(experiment *model.Experiment) error {
+	return r.db.Create(experiment).Error
+}
+
+func (r *experimentRepository) FindAllExperiments(promptName string) ([]model.Experiment, error) {
+	var experiments []model.Experiment
+	query := r.db.Preload("Arms", orderArms)
+	if promptName != "" {
+		query = query.Where("prompt_name = ?", promptName)
+	}
+	err := query.Order("created_at DESC").Find(&experiments).Error
+	return experiments, err
+}
+
+func (r *experimentRepository) FindExperimentByID(id uint) (*model.Experiment, error) {
+	var experiment model.Experiment
+	err := r.db.Preload("Arms", orderArms).First(&experiment, id).Error
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+func (r *experimentRepository) FindRunningExperiment(promptName string) (*model.Experiment, error) {
+	var experiment model.Experiment
+	err := r.db.Preload("Arms", orderArms).
+		Where("prompt_name = ? AND status = ?", promptName, model.ExperimentStatusRunning).
+		Order("started_at DESC").First(&experiment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &experiment, nil
+}
+
+// orderArms keeps arms in creation order, which sticky assignment relies on.
+func orderArms(db *gorm.DB) *gorm.DB {
+	return db.Order("id ASC")
+}
+
+func (r *experimentRepository) UpdateExperiment(experiment *model.Experiment) error {
+	return r.db.Omit("Arms").Save(experiment).Error
+}
+
+// FindArmStats aggregates evaluation scores, message counts and durations of
+// the interviews assigned to each arm. Only succeeded evaluations count towards
+// the scores. Arms without interviews are included.
+func (r *experimentRepository) FindArmStats(experimentID uint) ([]model.ExperimentArmStats, error) {
+	var stats []model.ExperimentArmStats
+	err := r.db.Raw(`
+		SELECT
+			a.id AS arm_id,
+			a.name AS arm_name,
+			a.version AS version,
+			COUNT(i.id) AS interviews,
+			COUNT(i.id) FILTER (WHERE i.status = ?) AS completed_interviews,
+			COUNT(e.id) AS evaluations,
+			AVG(e.overall_score) AS avg_overall_score,
+			AVG(e.problem_solving_score) AS avg_problem_solving_score,
+			AVG(e.code_quality_score) AS avg_code_quality_score,
+			AVG(e.communication_score) AS avg_communication_score,
+			AVG(e.technical_score) AS avg_technical_score,
+			AVG(COALESCE(mc.messages, 0)) FILTER (WHERE i.id IS NOT NULL) AS avg_messages,
+			AVG(EXTRACT(EPOCH FROM (i.ended_at - i.started_at))) AS avg_duration_seconds
+		FROM experiment_arms a
+		LEFT JOIN interviews i ON i.experiment_arm_id = a.id
+		LEFT JOIN evaluations e ON e.interview_id = i.id AND e.status = ?
+		LEFT JOIN (
+			SELECT interview_id, COUNT(*) AS messages FROM messages GROUP BY interview_id
+		) mc ON mc.interview_id = i.id
+		WHERE a.experiment_id = ?
+		GROUP BY a.id, a.name, a.version
+		ORDER BY a.id`, model.InterviewStatusCompleted, model.EvaluationStatusSucceeded, experimentID).Scan(&stats).Error
+	return stats, err
+}
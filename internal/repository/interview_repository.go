@@ -2,16 +2,74 @@ package repository
 
 import (
 	"minos/internal/model"
+	"time"
 
 	"github.com/google/uuid"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
+// Sort keys accepted by SearchInterviews.
+const (
+	InterviewSortStartedAt    = "started_at"
+	InterviewSortOverallScore = "overall_score"
+)
+
+// InterviewFilter selects and orders interview summaries. Results are paged
+// by keyset: After is the sort key and ID of the last row already returned.
+type InterviewFilter struct {
+	UserID    *uuid.UUID
+	Statuses  []model.InterviewStatus
+	ProblemID *uuid.UUID
+	From      *time.Time
+	To        *time.Time
+	SortBy    string
+	Desc      bool
+	After     *InterviewCursor
+	Limit     int
+}
+
+// InterviewCursor is the position after which the next page starts. Only the
+// field matching the filter's SortBy is used.
+type InterviewCursor struct {
+	StartedAt    time.Time `json:"started_at,omitempty"`
+	OverallScore int       `json:"overall_score,omitempty"`
+	ID           uuid.UUID `json:"id"`
+}
+
 type InterviewRepository interface {
 	CreateInterview(interview *model.Interview) error
+	// CreateInterviewWithinLimit creates interview unless its user already has
+	// max interviews in one of statuses, and reports whether it did. Creations
+	// for the same user are serialized, so concurrent ones cannot both pass.
+	CreateInterviewWithinLimit(interview *model.Interview, max int, statuses ...model.InterviewStatus) (bool, error)
 	FindInterviewByID(id uuid.UUID) (*model.Interview, error)
 	FindInterviewsByUserID(userID uuid.UUID) ([]model.Interview, error)
+	FindInterviewOwner(id uuid.UUID) (uuid.UUID, error)
 	UpdateInterview(interview *model.Interview) error
+	// FindIdleInterviews returns active interviews whose last message, start
+	// and last resume are all older than cutoff.
+	FindIdleInterviews(cutoff time.Time, limit int) ([]model.Interview, error)
+	// FindStalePausedInterviews returns interviews paused since before cutoff.
+	// Ones paused before pauses were timed count from their last message.
+	FindStalePausedInterviews(cutoff time.Time, limit int) ([]model.Interview, error)
+	// FindExpiredInterviews returns active timed interviews whose active time
+	// has reached their duration at now.
+	FindExpiredInterviews(now time.Time, limit int) ([]model.Interview, error)
+	// TransitionInterview saves the status and clock fields of interview only
+	// if it is still in the from status, and reports whether it was.
+	TransitionInterview(interview *model.Interview, from model.InterviewStatus) (bool, error)
+	SearchInterviews(filter InterviewFilter) ([]model.InterviewSummary, error)
+	// SaveTranscriptSummary stores the transcript summary fields of interview
+	// and adds usage to its summary usage, provided the stored summary still
+	// ends at previous. It reports whether it was saved.
+	SaveTranscriptSummary(interview *model.Interview, previous *uuid.UUID, usage model.TokenUsage) (bool, error)
+	// ClaimHint counts one more hint against an interview unless it already
+	// used max, and returns the new count, or 0 when none was left.
+	ClaimHint(id uuid.UUID, max int) (int, error)
+	// ReleaseHint takes back a claimed hint that could not be given, provided
+	// no later one was claimed since.
+	ReleaseHint(id uuid.UUID, claimed int) error
 }
 
 type interviewRepository struct {
@@ -26,10 +84,31 @@ func (r *interviewRepository) CreateInterview(interview *model.Interview) error
 	return r.db.Create(interview).Error
 }
 
+func (r *interviewRepository) CreateInterviewWithinLimit(interview *model.Interview, max int, statuses ...model.InterviewStatus) (bool, error) {
+	created := false
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		// Held until the transaction ends, keyed by user so other users never wait
+		if err := tx.Exec("SELECT pg_advisory_xact_lock(hashtext(?))", "interviews:"+interview.UserID.String()).Error; err != nil {
+			return err
+		}
+		var count int64
+		if err := tx.Model(&model.Interview{}).Where("user_id = ? AND status IN ?", interview.UserID, statuses).Count(&count).Error; err != nil {
+			return err
+		}
+		if count >= int64(max) {
+			return nil
+		}
+		created = true
+		return tx.Create(interview).Error
+	})
+	return created && err == nil, err
+}
+
 func (r *interviewRepository) FindInterviewByID(id uuid.UUID) (*model.Interview, error) {
 	var interview model.Interview
-	// Preload related data
-	err := r.db.Preload("Messages").Preload("Submissions").Preload("Evaluation").First(&interview, id).Error
+	// Preload related data. The interviewer instruction is kept for auditing
+	// only and must not reach the candidate.
+	err := r.db.Preload("Messages", "role <> ?", model.MessageRoleSystem).Preload("Submissions").Preload("Evaluation").First(&interview, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -42,7 +121,138 @@ func (r *interviewRepository) FindInterviewsByUserID(userID uuid.UUID) ([]model.
 	return interviews, err
 }
 
+// FindInterviewOwner returns the user of an interview without loading it.
+func (r *interviewRepository) FindInterviewOwner(id uuid.UUID) (uuid.UUID, error) {
+	var interview model.Interview
+	err := r.db.Select("id", "user_id").First(&interview, "id = ?", id).Error
+	return interview.UserID, err
+}
+
 func (r *interviewRepository) UpdateInterview(interview *model.Interview) error {
 	return r.db.Save(interview).Error
 }
 
+func (r *interviewRepository) FindIdleInterviews(cutoff time.Time, limit int) ([]model.Interview, error) {
+	var interviews []model.Interview
+	err := r.db.
+		Where("status = ?", model.InterviewStatusActive).
+		Where("GREATEST(COALESCE((SELECT MAX(m.created_at) FROM messages m WHERE m.interview_id = interviews.id), interviews.started_at), COALESCE(interviews.resumed_at, interviews.started_at)) < ?", cutoff).
+		Order("started_at ASC").
+		Limit(limit).
+		Find(&interviews).Error
+	return interviews, err
+}
+
+func (r *interviewRepository) FindStalePausedInterviews(cutoff time.Time, limit int) ([]model.Interview, error) {
+	var interviews []model.Interview
+	err := r.db.
+		Where("status = ?", model.InterviewStatusPaused).
+		Where("COALESCE(paused_at, (SELECT MAX(m.created_at) FROM messages m WHERE m.interview_id = interviews.id), interviews.started_at) < ?", cutoff).
+		Order("started_at ASC").
+		Limit(limit).
+		Find(&interviews).Error
+	return interviews, err
+}
+
+func (r *interviewRepository) FindExpiredInterviews(now time.Time, limit int) ([]model.Interview, error) {
+	var interviews []model.Interview
+	err := r.db.
+		Where("status = ? AND duration_minutes > 0 AND resumed_at IS NOT NULL", model.InterviewStatusActive).
+		Where("active_seconds + EXTRACT(EPOCH FROM (?::timestamptz - resumed_at)) >= duration_minutes * 60", now).
+		Order("started_at ASC").
+		Limit(limit).
+		Find(&interviews).Error
+	return interviews, err
+}
+
+func (r *interviewRepository) TransitionInterview(interview *model.Interview, from model.InterviewStatus) (bool, error) {
+	result := r.db.Model(interview).
+		Where("status = ?", from).
+		Select("status", "active_seconds", "resumed_at", "paused_at", "ended_at").
+		Updates(interview)
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *interviewRepository) SaveTranscriptSummary(interview *model.Interview, previous *uuid.UUID, usage model.TokenUsage) (bool, error) {
+	result := r.db.Model(&model.Interview{}).
+		Where("id = ? AND summarized_through IS NOT DISTINCT FROM ?", interview.ID, previous).
+		Updates(map[string]interface{}{
+			"transcript_summary":        interview.TranscriptSummary,
+			"summarized_through":        interview.SummarizedThrough,
+			"preserved_code":            interview.PreservedCode,
+			"summary_model":             interview.SummaryModel,
+			"summary_prompt_tokens":     gorm.Expr("summary_prompt_tokens + ?", usage.PromptTokens),
+			"summary_completion_tokens": gorm.Expr("summary_completion_tokens + ?", usage.CompletionTokens),
+			"summary_cost_usd":          gorm.Expr("summary_cost_usd + ?", usage.CostUSD),
+		})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *interviewRepository) ClaimHint(id uuid.UUID, max int) (int, error) {
+	var interview model.Interview
+	result := r.db.Model(&interview).
+		Clauses(clause.Returning{Columns: []clause.Column{{Name: "hints_used"}}}).
+		Where("id = ? AND hints_used < ?", id, max).
+		UpdateColumn("hints_used", gorm.Expr("hints_used + 1"))
+	if result.Error != nil || result.RowsAffected == 0 {
+		return 0, result.Error
+	}
+	return interview.HintsUsed, nil
+}
+
+func (r *interviewRepository) ReleaseHint(id uuid.UUID, claimed int) error {
+	return r.db.Model(&model.Interview{}).
+		Where("id = ? AND hints_used = ?", id, claimed).
+		UpdateColumn("hints_used", claimed-1).Error
+}
+
+// interviewScore is the overall score of a succeeded evaluation, -1 otherwise,
+// so unevaluated interviews sort below every scored one.
+const interviewScore = "COALESCE(CASE WHEN e.status = 'succeeded' THEN e.overall_score END, -1)"
+
+func (r *interviewRepository) SearchInterviews(filter InterviewFilter) ([]model.InterviewSummary, error) {
+	query := r.db.Table("interviews AS i").
+		Select(`i.id, i.user_id, i.problem_id, i.status, i.prompt_version, i.duration_minutes, i.started_at, i.ended_at,
+			e.status AS evaluation_status,
+			CASE WHEN e.status = 'succeeded' THEN e.overall_score END AS overall_score`).
+		Joins("LEFT JOIN evaluations e ON e.interview_id = i.id")
+
+	if filter.UserID != nil {
+		query = query.Where("i.user_id = ?", *filter.UserID)
+	}
+	if len(filter.Statuses) > 0 {
+		query = query.Where("i.status IN ?", filter.Statuses)
+	}
+	if filter.ProblemID != nil {
+		query = query.Where("i.problem_id = ?", *filter.ProblemID)
+	}
+	if filter.From != nil {
+		query = query.Where("i.started_at >= ?", *filter.From)
+	}
+	if filter.To != nil {
+		query = query.Where("i.started_at < ?", *filter.To)
+	}
+
+	key, direction, cmp := "i.started_at", "ASC", ">"
+	if filter.SortBy == InterviewSortOverallScore {
+		key = interviewScore
+	}
+	if filter.Desc {
+		direction, cmp = "DESC", "<"
+	}
+	if filter.After != nil {
+		var value interface{} = filter.After.StartedAt
+		if filter.SortBy == InterviewSortOverallScore {
+			value = filter.After.OverallScore
+		}
+		query = query.Where("("+key+", i.id) "+cmp+" (?, ?)", value, filter.After.ID)
+	}
+
+	var summaries []model.InterviewSummary
+	err := query.
+		Order(key + " " + direction).
+		Order("i.id " + direction).
+		Limit(filter.Limit).
+		Scan(&summaries).Error
+	return summaries, err
+}
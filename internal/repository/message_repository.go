@@ -9,7 +9,10 @@ import (
 
 type MessageRepository interface {
 	CreateMessage(message *model.Message) error
-	FindMessagesByInterviewID(interviewID uuid.UUID) ([]model.Message, error)
+	// FindMessagesByInterviewID returns the messages of an interview, oldest
+	// first, limited to the given roles when any are passed.
+	FindMessagesByInterviewID(interviewID uuid.UUID, roles ...model.MessageRole) ([]model.Message, error)
+	FindMessagesAfter(interviewID uuid.UUID, afterID uuid.UUID, roles ...model.MessageRole) ([]model.Message, error)
 }
 
 type messageRepository struct {
@@ -24,9 +27,27 @@ func (r *messageRepository) CreateMessage(message *model.Message) error {
 	return r.db.Create(message).Error
 }
 
-func (r *messageRepository) FindMessagesByInterviewID(interviewID uuid.UUID) ([]model.Message, error) {
+func (r *messageRepository) FindMessagesByInterviewID(interviewID uuid.UUID, roles ...model.MessageRole) ([]model.Message, error) {
 	var messages []model.Message
-	err := r.db.Where("interview_id = ?", interviewID).Order("created_at ASC").Find(&messages).Error
+	err := withRoles(r.db.Where("interview_id = ?", interviewID), roles).Order("created_at ASC").Find(&messages).Error
 	return messages, err
 }
 
+// FindMessagesAfter returns the messages created after the given message, oldest first.
+func (r *messageRepository) FindMessagesAfter(interviewID uuid.UUID, afterID uuid.UUID, roles ...model.MessageRole) ([]model.Message, error) {
+	var after model.Message
+	if err := r.db.Where("interview_id = ? AND id = ?", interviewID, afterID).First(&after).Error; err != nil {
+		return nil, err
+	}
+
+	var messages []model.Message
+	err := withRoles(r.db.Where("interview_id = ? AND created_at > ?", interviewID, after.CreatedAt), roles).Order("created_at ASC").Find(&messages).Error
+	return messages, err
+}
+
+func withRoles(query *gorm.DB, roles []model.MessageRole) *gorm.DB {
+	if len(roles) == 0 {
+		return query
+	}
+	return query.Where("role IN ?", roles)
+}
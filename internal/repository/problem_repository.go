@@ -0,0 +1,83 @@
+package repository
+
+import (
+	"encoding/json"
+	"minos/internal/model"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ProblemFilter selects problems of the problem bank. Empty fields match any problem.
+type ProblemFilter struct {
+	Difficulty model.ProblemDifficulty
+	Tag        string
+	Search     string // Case-insensitive match on the title or slug
+}
+
+type ProblemRepository interface {
+	CreateProblem(problem *model.Problem) error
+	FindProblems(filter ProblemFilter) ([]model.Problem, error)
+	FindProblemByID(id uuid.UUID) (*model.Problem, error)
+	FindProblemBySlug(slug string) (*model.Problem, error)
+	UpdateProblem(problem *model.Problem) error
+	DeleteProblem(id uuid.UUID) error
+}
+
+type problemRepository struct {
+	db *gorm.DB
+}
+
+func NewProblemRepository(db *gorm.DB) ProblemRepository {
+	return &problemRepository{db: db}
+}
+
+func (r *problemRepository) CreateProblem(problem *model.Problem) error {
+	return r.db.Create(problem).Error
+}
+
+func (r *problemRepository) FindProblems(filter ProblemFilter) ([]model.Problem, error) {
+	var problems []model.Problem
+	query := r.db
+	if filter.Difficulty != "" {
+		query = query.Where("difficulty = ?", filter.Difficulty)
+	}
+	if filter.Tag != "" {
+		tag, _ := json.Marshal([]string{filter.Tag})
+		query = query.Where("tags @> ?", string(tag))
+	}
+	if filter.Search != "" {
+		pattern := "%" + filter.Search + "%"
+		query = query.Where("title ILIKE ? OR slug ILIKE ?", pattern, pattern)
+	}
+	err := query.Order("title ASC").Find(&problems).Error
+	return problems, err
+}
+
+func (r *problemRepository) FindProblemByID(id uuid.UUID) (*model.Problem, error) {
+	var problem model.Problem
+	if err := r.db.First(&problem, "id = ?", id).Error; err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+func (r *problemRepository) FindProblemBySlug(slug string) (*model.Problem, error) {
+	var problem model.Problem
+	if err := r.db.Where("slug = ?", slug).First(&problem).Error; err != nil {
+		return nil, err
+	}
+	return &problem, nil
+}
+
+func (r *problemRepository) UpdateProblem(problem *model.Problem) error {
+	return r.db.Save(problem).Error
+}
+
+func (r *problemRepository) DeleteProblem(id uuid.UUID) error {
+	result := r.db.Delete(&model.Problem{}, "id = ?", id)
+	if result.Error == nil && result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return result.Error
+}
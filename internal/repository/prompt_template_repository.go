@@ -1,9 +1,12 @@
 package repository
 
 import (
+	"errors"
+	"fmt"
 	"minos/internal/model"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PromptTemplateRepository interface {
@@ -12,8 +15,16 @@ type PromptTemplateRepository interface {
 	FindAllPromptTemplates(name, version string, isActive *bool) ([]model.PromptTemplate, error)
 	FindPromptTemplateByID(id uint) (*model.PromptTemplate, error)
 	FindPromptTemplateByNameVersion(name, version string) (*model.PromptTemplate, error)
+	FindActivePromptTemplateByName(name string) (*model.PromptTemplate, error)
 	UpdatePromptTemplate(template *model.PromptTemplate) error
 	DeletePromptTemplate(id uint) error
+
+	// Release channel methods
+	FindPromptChannel(name, channel string) (*model.PromptChannel, error)
+	FindPromptChannels(name string) ([]model.PromptChannel, error)
+	FindPromptChannelHistory(name, channel string) ([]model.PromptChannelHistory, error)
+	PromotePromptChannel(target *model.PromptTemplate, channel, actor string) (*model.PromptChannel, error)
+	RollbackPromptChannel(name, channel, actor string) (*model.PromptChannel, error)
 }
 
 type promptTemplateRepository struct {
@@ -65,6 +76,16 @@ func (r *promptTemplateRepository) FindPromptTemplateByNameVersion(name, version
 	return &template, nil
 }
 
+// FindActivePromptTemplateByName returns the most recently created active template with the given name.
+func (r *promptTemplateRepository) FindActivePromptTemplateByName(name string) (*model.PromptTemplate, error) {
+	var template model.PromptTemplate
+	err := r.db.Where("name = ? AND is_active = ?", name, true).Order("created_at DESC").First(&template).Error
+	if err != nil {
+		return nil, err
+	}
+	return &template, nil
+}
+
 func (r *promptTemplateRepository) UpdatePromptTemplate(template *model.PromptTemplate) error {
 	return r.db.Save(template).Error
 }
@@ -72,3 +93,127 @@ func (r *promptTemplateRepository) UpdatePromptTemplate(template *model.PromptTe
 func (r *promptTemplateRepository) DeletePromptTemplate(id uint) error {
 	return r.db.Delete(&model.PromptTemplate{}, id).Error
 }
+
+// Release channel methods
+func (r *promptTemplateRepository) FindPromptChannel(name, channel string) (*model.PromptChannel, error) {
+	var pc model.PromptChannel
+	err := r.db.Where("name = ? AND channel = ?", name, channel).First(&pc).Error
+	if err != nil {
+		return nil, err
+	}
+	return &pc, nil
+}
+
+func (r *promptTemplateRepository) FindPromptChannels(name string) ([]model.PromptChannel, error) {
+	var channels []model.PromptChannel
+	query := r.db.Model(&model.PromptChannel{})
+	if name != "" {
+		query = query.Where("name = ?", name)
+	}
+	err := query.Order("name ASC, channel ASC").Find(&channels).Error
+	return channels, err
+}
+
+func (r *promptTemplateRepository) FindPromptChannelHistory(name, channel string) ([]model.PromptChannelHistory, error) {
+	var history []model.PromptChannelHistory
+	err := r.db.Where("name = ? AND channel = ?", name, channel).Order("created_at DESC, id DESC").Find(&history).Error
+	return history, err
+}
+
+// PromotePromptChannel points the channel at target and records the change,
+// all in one transaction holding a row lock on the channel.
+func (r *promptTemplateRepository) PromotePromptChannel(target *model.PromptTemplate, channel, actor string) (*model.PromptChannel, error) {
+	var result *model.PromptChannel
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		var err error
+		result, err = movePromptChannel(tx, target, channel, model.PromptChannelActionPromote, actor)
+		return err
+	})
+	return result, err
+}
+
+// RollbackPromptChannel moves the channel back to the version it pointed at
+// before the promotion of its current version.
+func (r *promptTemplateRepository) RollbackPromptChannel(name, channel, actor string) (*model.PromptChannel, error) {
+	var result *model.PromptChannel
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		current, err := lockPromptChannel(tx, name, channel)
+		if err != nil {
+			return err
+		}
+		if current == nil {
+			return fmt.Errorf("channel '%s' of prompt '%s' has never been promoted", channel, name)
+		}
+
+		var promotion model.PromptChannelHistory
+		err = tx.Where("name = ? AND channel = ? AND action = ? AND to_template_id = ?",
+			name, channel, model.PromptChannelActionPromote, current.TemplateID).
+			Order("created_at DESC, id DESC").First(&promotion).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if err != nil || promotion.FromTemplateID == nil {
+			return fmt.Errorf("channel '%s' of prompt '%s' has no previous version to roll back to", channel, name)
+		}
+
+		var target model.PromptTemplate
+		if err := tx.First(&target, *promotion.FromTemplateID).Error; err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return fmt.Errorf("previous version '%s' of prompt '%s' no longer exists", promotion.FromVersion, name)
+			}
+			return err
+		}
+
+		result, err = movePromptChannel(tx, &target, channel, model.PromptChannelActionRollback, actor)
+		return err
+	})
+	return result, err
+}
+
+func lockPromptChannel(tx *gorm.DB, name, channel string) (*model.PromptChannel, error) {
+	var pc model.PromptChannel
+	err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("name = ? AND channel = ?", name, channel).First(&pc).Error
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &pc, nil
+}
+
+func movePromptChannel(tx *gorm.DB, target *model.PromptTemplate, channel, action, actor string) (*model.PromptChannel, error) {
+	current, err := lockPromptChannel(tx, target.Name, channel)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &model.PromptChannelHistory{
+		Name:         target.Name,
+		Channel:      channel,
+		Action:       action,
+		ToTemplateID: target.ID,
+		ToVersion:    target.Version,
+		PerformedBy:  actor,
+	}
+
+	if current == nil {
+		current = &model.PromptChannel{Name: target.Name, Channel: channel}
+	} else {
+		fromID := current.TemplateID
+		entry.FromTemplateID = &fromID
+		entry.FromVersion = current.Version
+	}
+	current.TemplateID = target.ID
+	current.Version = target.Version
+	current.UpdatedBy = actor
+
+	// Save inserts or updates; a concurrent first promotion loses on the unique index
+	if err := tx.Save(current).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Create(entry).Error; err != nil {
+		return nil, err
+	}
+	return current, nil
+}
@@ -0,0 +1,82 @@
+package repository
+
+import (
+	"database/sql"
+	"minos/internal/model"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// UsageFilter selects the LLM calls of a usage report: From inclusive, To
+// exclusive, optionally only those made for one user's interviews.
+type UsageFilter struct {
+	From   time.Time
+	To     time.Time
+	UserID *uuid.UUID
+}
+
+// UsageRepository reads the token usage recorded on messages, submissions,
+// evaluations and interview summaries as if it were one table of LLM calls.
+type UsageRepository interface {
+	SumInterviewUsage(interviewID uuid.UUID) (*model.UsageTotals, error)
+	FindDailyUsage(filter UsageFilter) ([]model.DailyUsage, error)
+}
+
+type usageRepository struct {
+	db *gorm.DB
+}
+
+func NewUsageRepository(db *gorm.DB) UsageRepository {
+	return &usageRepository{db: db}
+}
+
+// llmCalls is every record an LLM call produced, with the interview it
+// belongs to and when it was made. Transcript summaries are accumulated on
+// the interview and count as made when it started.
+const llmCalls = `
+	SELECT interview_id, created_at AS at, model, prompt_tokens, completion_tokens, cost_usd FROM messages
+	UNION ALL
+	SELECT interview_id, submitted_at, model, prompt_tokens, completion_tokens, cost_usd FROM submissions
+	UNION ALL
+	SELECT interview_id, created_at, model, prompt_tokens, completion_tokens, cost_usd FROM evaluations
+	UNION ALL
+	SELECT id, started_at, summary_model, summary_prompt_tokens, summary_completion_tokens, summary_cost_usd FROM interviews`
+
+const usageTotals = `
+	COUNT(*) AS calls,
+	COALESCE(SUM(c.prompt_tokens), 0) AS prompt_tokens,
+	COALESCE(SUM(c.completion_tokens), 0) AS completion_tokens,
+	COALESCE(SUM(c.prompt_tokens + c.completion_tokens), 0) AS total_tokens,
+	COALESCE(SUM(c.cost_usd), 0) AS cost_usd`
+
+func (r *usageRepository) SumInterviewUsage(interviewID uuid.UUID) (*model.UsageTotals, error) {
+	var totals model.UsageTotals
+	err := r.db.Raw(`SELECT`+usageTotals+`
+		FROM (`+llmCalls+`) c
+		WHERE c.interview_id = @id AND c.prompt_tokens + c.completion_tokens > 0`,
+		sql.Named("id", interviewID),
+	).Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+	return &totals, nil
+}
+
+func (r *usageRepository) FindDailyUsage(filter UsageFilter) ([]model.DailyUsage, error) {
+	query := `SELECT to_char(c.at AT TIME ZONE 'UTC', 'YYYY-MM-DD') AS day, COALESCE(c.model, '') AS model,` + usageTotals + `
+		FROM (` + llmCalls + `) c
+		JOIN interviews i ON i.id = c.interview_id
+		WHERE c.prompt_tokens + c.completion_tokens > 0 AND c.at >= @from AND c.at < @to`
+	args := []interface{}{sql.Named("from", filter.From), sql.Named("to", filter.To)}
+	if filter.UserID != nil {
+		query += ` AND i.user_id = @user_id`
+		args = append(args, sql.Named("user_id", *filter.UserID))
+	}
+	query += ` GROUP BY 1, 2 ORDER BY 1, 2`
+
+	var rows []model.DailyUsage
+	err := r.db.Raw(query, args...).Scan(&rows).Error
+	return rows, err
+}
@@ -0,0 +1,84 @@
+package runner
+
+import (
+	"os/exec"
+	"strings"
+)
+
+type languageSpec struct {
+	source  string   // File the code is written to
+	compile []string // Optional build step
+	run     []string // Executed once per test case
+}
+
+var languages = map[string]languageSpec{
+	"python": {
+		source: "main.py",
+		run:    []string{"python3", "main.py"},
+	},
+	"go": {
+		source:  "main.go",
+		compile: []string{"go", "build", "-o", "main", "main.go"},
+		run:     []string{"./main"},
+	},
+	"javascript": {
+		source: "main.js",
+		run:    []string{"node", "main.js"},
+	},
+	"cpp": {
+		source:  "main.cpp",
+		compile: []string{"g++", "-O2", "-std=c++17", "-o", "main", "main.cpp"},
+		run:     []string{"./main"},
+	},
+}
+
+var languageAliases = map[string]string{
+	"python3": "python",
+	"py":      "python",
+	"golang":  "go",
+	"js":      "javascript",
+	"node":    "javascript",
+	"c++":     "cpp",
+	"cxx":     "cpp",
+}
+
+// canonicalLanguage resolves aliases to the name languages is keyed by.
+func canonicalLanguage(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if alias, ok := languageAliases[name]; ok {
+		return alias
+	}
+	return name
+}
+
+func lookupLanguage(name string) (languageSpec, bool) {
+	spec, ok := languages[canonicalLanguage(name)]
+	return spec, ok
+}
+
+// toolchain lists the host binaries the language needs. A run step that
+// executes the build output needs nothing beyond the compiler.
+func (l languageSpec) toolchain() []string {
+	var tools []string
+	if len(l.compile) > 0 {
+		tools = append(tools, l.compile[0])
+	}
+	if !strings.HasPrefix(l.run[0], "./") {
+		tools = append(tools, l.run[0])
+	}
+	return tools
+}
+
+// installedLanguages returns the languages whose toolchain is on PATH.
+func installedLanguages() map[string]bool {
+	installed := make(map[string]bool, len(languages))
+	for name, spec := range languages {
+		installed[name] = true
+		for _, tool := range spec.toolchain() {
+			if _, err := exec.LookPath(tool); err != nil {
+				installed[name] = false
+			}
+		}
+	}
+	return installed
+}
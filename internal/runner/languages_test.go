@@ -0,0 +1,36 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCanonicalLanguage(t *testing.T) {
+	tests := map[string]string{
+		"python":   "python",
+		" Python3": "python",
+		"golang":   "go",
+		"C++":      "cpp",
+		"node":     "javascript",
+		"rust":     "rust",
+	}
+	for name, want := range tests {
+		if got := canonicalLanguage(name); got != want {
+			t.Errorf("canonicalLanguage(%q) = %q, want %q", name, got, want)
+		}
+	}
+}
+
+func TestToolchain(t *testing.T) {
+	tests := map[string][]string{
+		"python":     {"python3"},
+		"javascript": {"node"},
+		"go":         {"go"},
+		"cpp":        {"g++"},
+	}
+	for name, want := range tests {
+		if got := languages[name].toolchain(); !reflect.DeepEqual(got, want) {
+			t.Errorf("toolchain(%q) = %v, want %v", name, got, want)
+		}
+	}
+}
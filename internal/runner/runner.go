@@ -0,0 +1,369 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"minos/config"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// Status values reported for each executed test case.
+const (
+	StatusPassed       = "passed"
+	StatusFailed       = "failed"
+	StatusCompileError = "compile_error"
+	StatusRuntimeError = "runtime_error"
+	StatusTimeout      = "timeout"
+)
+
+// maxOutputBytes caps captured stdout/stderr per run.
+const maxOutputBytes = 64 * 1024
+
+type TestCase struct {
+	Input    string `json:"input"`
+	Expected string `json:"expected"`
+	Hidden   bool   `json:"hidden,omitempty"` // Only the outcome of hidden cases is reported
+}
+
+type TestResult struct {
+	Input     string `json:"input"`
+	Expected  string `json:"expected"`
+	Stdout    string `json:"stdout"`
+	Stderr    string `json:"stderr,omitempty"`
+	Hidden    bool   `json:"hidden,omitempty"`
+	Passed    bool   `json:"passed"`
+	Status    string `json:"status"`
+	RuntimeMs int64  `json:"runtime_ms"`
+	MemoryKB  int64  `json:"memory_kb"`
+}
+
+// Runner executes candidate code against test cases.
+type Runner interface {
+	Supports(language string) bool
+	Run(ctx context.Context, language, code string, tests []TestCase) ([]TestResult, error)
+}
+
+type localRunner struct {
+	cfg       config.RunnerConfig
+	installed map[string]bool // Languages whose toolchain was found at startup
+}
+
+// NewRunner checks once that sandboxes can be created and which toolchains
+// are installed, so submissions in other languages keep the reviewer's verdict
+// rather than failing every test.
+func NewRunner(cfg *config.Config) Runner {
+	r := &localRunner{cfg: cfg.Runner}
+	if r.cfg.Enabled {
+		if err := sandboxAvailable(r.cfg.IsolateNetwork); err != nil {
+			log.Error().Err(err).Msg("Code runner disabled, code cannot be sandboxed")
+			r.cfg.Enabled = false
+		}
+	}
+	var supported []string
+	if r.cfg.Enabled {
+		r.installed = installedLanguages()
+		for name, ok := range r.installed {
+			if ok {
+				supported = append(supported, name)
+			} else {
+				log.Warn().Str("language", name).Strs("toolchain", languages[name].toolchain()).Msg("Toolchain not found, code in this language will not be executed")
+			}
+		}
+		sort.Strings(supported)
+	}
+	log.Info().Bool("enabled", r.cfg.Enabled).Strs("languages", supported).Int("uid", r.cfg.UID).Bool("isolate_network", r.cfg.IsolateNetwork).Msg("Code runner initialized")
+	return r
+}
+
+func (r *localRunner) Supports(language string) bool {
+	return r.cfg.Enabled && r.installed[canonicalLanguage(language)]
+}
+
+// Run compiles the code once in a fresh temp dir, then runs it for every test
+// case as a separate sandboxed process. Compilation is sandboxed and limited
+// the same way, with its own time and memory limits. A sandbox that cannot be
+// set up fails the run rather than the test.
+func (r *localRunner) Run(ctx context.Context, language, code string, tests []TestCase) ([]TestResult, error) {
+	if !r.cfg.Enabled {
+		return nil, errors.New("code runner is disabled")
+	}
+	lang, ok := lookupLanguage(language)
+	if !ok {
+		return nil, fmt.Errorf("unsupported language: %s", language)
+	}
+
+	dir, err := os.MkdirTemp(r.cfg.WorkDir, "minos-run-")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sandbox dir: %w", err)
+	}
+	defer os.RemoveAll(dir)
+	run := sandboxDirs{root: filepath.Join(dir, "root"), work: filepath.Join(dir, "work")}
+	if run.cache, err = r.goCache(); err != nil {
+		return nil, fmt.Errorf("failed to prepare build cache: %w", err)
+	}
+	if err := r.prepare(run, lang.source, code); err != nil {
+		return nil, fmt.Errorf("failed to write source: %w", err)
+	}
+
+	if len(lang.compile) > 0 {
+		timeout := time.Duration(r.cfg.CompileTimeoutSeconds) * time.Second
+		out, err := r.exec(ctx, run, lang.compile, "", timeout, sandboxLimits{
+			CPUSeconds:  uint64(r.cfg.CompileTimeoutSeconds) + 1,
+			MemoryBytes: uint64(r.cfg.CompileMemoryLimitMB) << 20,
+			FileBytes:   256 << 20, // Room for the binary and object files
+			Processes:   uint64(r.cfg.MaxProcesses),
+		})
+		if err != nil {
+			return nil, err
+		}
+		if out.status != StatusPassed {
+			results := make([]TestResult, 0, len(tests))
+			for _, tc := range tests {
+				results = append(results, reported(tc, TestResult{
+					Input:    tc.Input,
+					Expected: tc.Expected,
+					Stderr:   out.stderr,
+					Status:   StatusCompileError,
+				}))
+			}
+			return results, nil
+		}
+	}
+
+	timeout := time.Duration(r.cfg.TimeoutSeconds) * time.Second
+	limits := sandboxLimits{
+		CPUSeconds:  uint64(r.cfg.TimeoutSeconds) + 1,
+		MemoryBytes: uint64(r.cfg.MemoryLimitMB) << 20,
+		FileBytes:   1 << 20, // About 1MB of scratch files
+		Processes:   uint64(r.cfg.MaxProcesses),
+	}
+	// The cache is only written by builds, never by the code itself, which
+	// could otherwise plant entries later builds reuse
+	run.cacheReadOnly = true
+	results := make([]TestResult, 0, len(tests))
+	for _, tc := range tests {
+		out, err := r.exec(ctx, run, lang.run, tc.Input, timeout, limits)
+		if err != nil {
+			return nil, err
+		}
+		res := TestResult{
+			Input:     tc.Input,
+			Expected:  tc.Expected,
+			Stdout:    out.stdout,
+			Stderr:    out.stderr,
+			Status:    out.status,
+			RuntimeMs: out.runtime.Milliseconds(),
+			MemoryKB:  out.memoryKB,
+		}
+		if out.status == StatusPassed {
+			res.Passed = normalize(out.stdout) == normalize(tc.Expected)
+			if !res.Passed {
+				res.Status = StatusFailed
+			}
+		}
+		results = append(results, reported(tc, res))
+	}
+	return results, nil
+}
+
+// reported strips what a hidden test case would give away from its result,
+// leaving the outcome and timing.
+func reported(tc TestCase, res TestResult) TestResult {
+	if !tc.Hidden {
+		return res
+	}
+	return TestResult{
+		Hidden:    true,
+		Passed:    res.Passed,
+		Status:    res.Status,
+		RuntimeMs: res.RuntimeMs,
+		MemoryKB:  res.MemoryKB,
+	}
+}
+
+type execResult struct {
+	stdout   string
+	stderr   string
+	status   string
+	runtime  time.Duration
+	memoryKB int64
+}
+
+// sandboxDirs are the host dirs of one run.
+type sandboxDirs struct {
+	root  string // Where the sandbox root is assembled
+	work  string // Source and build output, the code's working dir
+	cache string // Build cache shared by every run
+
+	cacheReadOnly bool
+}
+
+// prepare creates the run's dirs and writes the source, owned by the sandbox
+// user so the code can build in place.
+func (r *localRunner) prepare(run sandboxDirs, source, code string) error {
+	for _, dir := range []string{run.root, run.work} {
+		if err := os.Mkdir(dir, 0o700); err != nil {
+			return err
+		}
+	}
+	path := filepath.Join(run.work, source)
+	if err := os.WriteFile(path, []byte(code), 0o600); err != nil {
+		return err
+	}
+	for _, p := range []string{run.work, path} {
+		if err := os.Chown(p, r.cfg.UID, r.cfg.GID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// exec runs argv in a sandbox built from run, re-executing the service binary
+// to set it up and apply limits before exec'ing the target. It only returns
+// an error when the sandbox could not be set up; what the code does is
+// reported in the result.
+func (r *localRunner) exec(ctx context.Context, run sandboxDirs, argv []string, stdin string, timeout time.Duration, limits sandboxLimits) (execResult, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	self, err := os.Executable()
+	if err != nil {
+		return execResult{}, fmt.Errorf("failed to locate service binary: %w", err)
+	}
+	spec, err := json.Marshal(sandboxSpec{
+		Root:          run.root,
+		Work:          run.work,
+		Cache:         run.cache,
+		CacheReadOnly: run.cacheReadOnly,
+		Mounts:        r.cfg.Mounts,
+		UID:           r.cfg.UID,
+		GID:           r.cfg.GID,
+		Limits:        limits,
+		Argv:          argv,
+		Env:           sandboxEnv(),
+	})
+	if err != nil {
+		return execResult{}, err
+	}
+	// Setup errors come back on their own pipe, closed once the code is
+	// exec'd, so nothing the code prints or exits with passes for one
+	setupErrs, setupErrsW, err := os.Pipe()
+	if err != nil {
+		return execResult{}, err
+	}
+	defer setupErrs.Close()
+
+	cmd := exec.CommandContext(ctx, self, SandboxCommand, string(spec))
+	cmd.Env = []string{}
+	cmd.Stdin = strings.NewReader(stdin)
+	stdout := &limitedBuffer{limit: maxOutputBytes}
+	stderr := &limitedBuffer{limit: maxOutputBytes}
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+	cmd.ExtraFiles = []*os.File{setupErrsW}
+	configureSandbox(cmd, r.cfg.IsolateNetwork)
+	cmd.Cancel = func() error { return killProcessGroup(cmd) }
+
+	start := time.Now()
+	err = cmd.Start()
+	setupErrsW.Close()
+	if err != nil {
+		return execResult{}, fmt.Errorf("failed to start sandbox: %w", err)
+	}
+	err = cmd.Wait()
+	res := execResult{
+		stdout:  stdout.String(),
+		stderr:  stderr.String(),
+		status:  StatusPassed,
+		runtime: time.Since(start),
+	}
+	if setupErr, _ := io.ReadAll(io.LimitReader(setupErrs, maxOutputBytes)); len(setupErr) > 0 {
+		return res, fmt.Errorf("failed to set up sandbox: %s", strings.TrimSpace(string(setupErr)))
+	}
+	if cmd.ProcessState != nil {
+		res.memoryKB = peakMemoryKB(cmd.ProcessState)
+	}
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		res.status = StatusTimeout
+	case err != nil:
+		res.status = StatusRuntimeError
+		if res.stderr == "" {
+			res.stderr = err.Error()
+		}
+	}
+	return res, nil
+}
+
+// sandboxEnv gives the code a minimal environment rooted in its work dir so
+// it cannot read the service's secrets from env vars. GOMAXPROCS keeps Go
+// toolchains within the process limit on machines with many cores.
+func sandboxEnv() []string {
+	return []string{
+		"PATH=" + os.Getenv("PATH"),
+		"HOME=" + sandboxWorkDir,
+		"TMPDIR=" + sandboxWorkDir,
+		"GOCACHE=" + sandboxCacheDir,
+		"GOPATH=" + sandboxWorkDir + "/.gopath",
+		"GO111MODULE=off",
+		"GOTOOLCHAIN=local",
+		"CGO_ENABLED=0",
+		"GOMAXPROCS=2",
+	}
+}
+
+// goCache is shared across runs so Go builds do not recompile the standard
+// library every time. It belongs to the sandbox user, one per UID.
+func (r *localRunner) goCache() (string, error) {
+	base := r.cfg.WorkDir
+	if base == "" {
+		base = os.TempDir()
+	}
+	cache := filepath.Join(base, fmt.Sprintf("minos-gocache-%d", r.cfg.UID))
+	if err := os.MkdirAll(cache, 0o700); err != nil {
+		return "", err
+	}
+	return cache, os.Chown(cache, r.cfg.UID, r.cfg.GID)
+}
+
+func normalize(s string) string {
+	lines := strings.Split(strings.TrimSpace(strings.ReplaceAll(s, "\r\n", "\n")), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	return strings.Join(lines, "\n")
+}
+
+// limitedBuffer keeps at most limit bytes and silently drops the rest, so a
+// runaway print loop cannot exhaust the service's memory.
+type limitedBuffer struct {
+	buf   bytes.Buffer
+	limit int
+}
+
+func (b *limitedBuffer) Write(p []byte) (int, error) {
+	if room := b.limit - b.buf.Len(); room > 0 {
+		if len(p) > room {
+			b.buf.Write(p[:room])
+		} else {
+			b.buf.Write(p)
+		}
+	}
+	return len(p), nil
+}
+
+func (b *limitedBuffer) String() string {
+	return b.buf.String()
+}
@@ -0,0 +1,28 @@
+package runner
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestReported(t *testing.T) {
+	res := TestResult{
+		Input:     "5 5",
+		Expected:  "10",
+		Stdout:    "11",
+		Stderr:    "warning",
+		Passed:    false,
+		Status:    StatusFailed,
+		RuntimeMs: 12,
+		MemoryKB:  2048,
+	}
+
+	if got := reported(TestCase{Input: "5 5", Expected: "10"}, res); !reflect.DeepEqual(got, res) {
+		t.Errorf("reported(visible) = %#v, want the result unchanged", got)
+	}
+
+	want := TestResult{Hidden: true, Status: StatusFailed, RuntimeMs: 12, MemoryKB: 2048}
+	if got := reported(TestCase{Input: "5 5", Expected: "10", Hidden: true}, res); !reflect.DeepEqual(got, want) {
+		t.Errorf("reported(hidden) = %#v, want %#v", got, want)
+	}
+}
@@ -0,0 +1,68 @@
+package runner
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// SandboxCommand is the argument the service binary is re-executed with to
+// set up a sandbox and exec candidate code in it. main hands it to RunSandbox.
+const SandboxCommand = "runner-sandbox"
+
+// sandboxProbe, passed instead of a spec, only checks that the namespaces of
+// a sandbox can be created.
+const sandboxProbe = "probe"
+
+// sandboxErrFD is where the re-executed binary reports setup errors. It is
+// the first of exec.Cmd.ExtraFiles and closed when the code is exec'd.
+const sandboxErrFD = 3
+
+// Paths code sees inside the sandbox.
+const (
+	sandboxWorkDir  = "/sandbox"
+	sandboxCacheDir = "/cache"
+)
+
+// sandboxLimits are the rlimits applied before exec'ing the code.
+type sandboxLimits struct {
+	CPUSeconds  uint64 `json:"cpu_seconds"`
+	MemoryBytes uint64 `json:"memory_bytes"` // Heap and anonymous mappings
+	FileBytes   uint64 `json:"file_bytes"`   // Largest file the code may write
+	Processes   uint64 `json:"processes"`    // Processes and threads of UID
+}
+
+// sandboxSpec tells the re-executed binary how to build the sandbox.
+type sandboxSpec struct {
+	Root   string        `json:"root"`   // Empty host dir the sandbox root is assembled on
+	Work   string        `json:"work"`   // Host dir mounted read-write at sandboxWorkDir
+	Cache  string        `json:"cache"`  // Host dir mounted at sandboxCacheDir
+	Mounts []string      `json:"mounts"` // Extra host paths mounted read-only at the same path
+	UID    int           `json:"uid"`
+	GID    int           `json:"gid"`
+	Limits sandboxLimits `json:"limits"`
+	Argv   []string      `json:"argv"`
+	Env    []string      `json:"env"`
+
+	CacheReadOnly bool `json:"cache_read_only"` // Set for steps running the code itself
+}
+
+// RunSandbox enters the sandbox described by args and execs the code in it,
+// so it only returns, with the exit code to use, when that fails. The error
+// goes to sandboxErrFD when the runner passed one, else to stderr.
+func RunSandbox(args []string) int {
+	if len(args) == 1 && args[0] == sandboxProbe {
+		return 0
+	}
+	var spec sandboxSpec
+	err := fmt.Errorf("expected one spec argument, got %d", len(args))
+	if len(args) == 1 {
+		if err = json.Unmarshal([]byte(args[0]), &spec); err == nil {
+			err = enterSandbox(&spec)
+		}
+	}
+	if _, werr := fmt.Fprintln(os.NewFile(sandboxErrFD, "sandbox-errors"), err); werr != nil {
+		fmt.Fprintln(os.Stderr, "sandbox:", err)
+	}
+	return 126
+}
@@ -0,0 +1,242 @@
+//go:build linux
+
+package runner
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+
+	"golang.org/x/sys/unix"
+)
+
+// systemDirs are mounted read-only into every sandbox so toolchains work.
+// Nothing else of the host, the service's own files included, is visible.
+var systemDirs = []string{"/usr", "/bin", "/sbin", "/lib", "/lib32", "/lib64", "/etc", "/opt"}
+
+// sandboxDevices are bound from the host into the sandbox's /dev.
+var sandboxDevices = []string{"/dev/null", "/dev/zero", "/dev/random", "/dev/urandom"}
+
+// sandboxAvailable reports why sandboxes cannot be created, if they cannot.
+// Beyond running as root, it starts the service binary in the namespaces of
+// a sandbox, which container runtimes commonly forbid.
+func sandboxAvailable(isolateNetwork bool) error {
+	if os.Geteuid() != 0 {
+		return errors.New("the service must run as root to drop sandboxed code to RUNNER_UID")
+	}
+	self, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("failed to locate service binary: %w", err)
+	}
+	cmd := exec.Command(self, SandboxCommand, sandboxProbe)
+	cmd.Env = []string{}
+	configureSandbox(cmd, isolateNetwork)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("failed to create sandbox namespaces: %w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// configureSandbox starts the child in its own process group and in fresh
+// mount, PID, IPC and UTS namespaces, plus a network namespace without any
+// interface when asked. Ending the child, PID 1 of its namespace, takes down
+// everything it forked.
+func configureSandbox(cmd *exec.Cmd, isolateNetwork bool) {
+	attr := &syscall.SysProcAttr{
+		Setpgid:    true,
+		Pdeathsig:  syscall.SIGKILL,
+		Cloneflags: syscall.CLONE_NEWNS | syscall.CLONE_NEWPID | syscall.CLONE_NEWIPC | syscall.CLONE_NEWUTS,
+	}
+	if isolateNetwork {
+		attr.Cloneflags |= syscall.CLONE_NEWNET
+	}
+	cmd.SysProcAttr = attr
+}
+
+// enterSandbox runs in the child started by configureSandbox. It assembles a
+// root from read-only system directories, the run's work dir and a fresh
+// /proc, chroots into it, applies the limits, drops to the sandbox user and
+// execs the code.
+func enterSandbox(spec *sandboxSpec) error {
+	// prctl settings are per thread and must hold on the one that execs
+	runtime.LockOSThread()
+	// The code must not be able to report setup errors of its own
+	syscall.CloseOnExec(sandboxErrFD)
+
+	if err := unix.Mount("", "/", "", unix.MS_REC|unix.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mounts private: %w", err)
+	}
+	root := spec.Root
+	if err := unix.Mount("tmpfs", root, "tmpfs", unix.MS_NOSUID|unix.MS_NODEV, "size=1m,mode=0755"); err != nil {
+		return fmt.Errorf("mount root: %w", err)
+	}
+
+	for _, dir := range append(systemDirs, spec.Mounts...) {
+		if err := bindMount(dir, filepath.Join(root, dir), true); err != nil {
+			return err
+		}
+	}
+	for _, dev := range sandboxDevices {
+		if err := bindMount(dev, filepath.Join(root, dev), false); err != nil {
+			return err
+		}
+	}
+	if err := bindMount(spec.Work, filepath.Join(root, sandboxWorkDir), false); err != nil {
+		return err
+	}
+	if err := bindMount(spec.Cache, filepath.Join(root, sandboxCacheDir), spec.CacheReadOnly); err != nil {
+		return err
+	}
+	if err := mountFresh(filepath.Join(root, "proc"), "proc", ""); err != nil {
+		return err
+	}
+	if err := mountFresh(filepath.Join(root, "tmp"), "tmpfs", "size=64m,mode=1777"); err != nil {
+		return err
+	}
+	if err := unix.Mount("", root, "", unix.MS_REMOUNT|unix.MS_RDONLY|unix.MS_NOSUID|unix.MS_NODEV, ""); err != nil {
+		return fmt.Errorf("seal root: %w", err)
+	}
+
+	if err := unix.Chroot(root); err != nil {
+		return fmt.Errorf("chroot: %w", err)
+	}
+	if err := os.Chdir(sandboxWorkDir); err != nil {
+		return err
+	}
+
+	if err := setLimits(spec.Limits); err != nil {
+		return err
+	}
+	if err := dropPrivileges(spec.UID, spec.GID); err != nil {
+		return err
+	}
+
+	for _, kv := range spec.Env {
+		if path, ok := strings.CutPrefix(kv, "PATH="); ok {
+			os.Setenv("PATH", path)
+		}
+	}
+	if len(spec.Argv) == 0 {
+		return errors.New("nothing to run")
+	}
+	bin, err := exec.LookPath(spec.Argv[0])
+	if err != nil {
+		return err
+	}
+	return unix.Exec(bin, spec.Argv, spec.Env)
+}
+
+// bindMount mounts src at dst, creating dst to match. Missing sources are
+// skipped and symlinks are recreated rather than followed, so /bin -> usr/bin
+// stays a link into the read-only /usr.
+func bindMount(src, dst string, readOnly bool) error {
+	info, err := os.Lstat(src)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o755); err != nil {
+		return err
+	}
+
+	switch {
+	case info.Mode()&os.ModeSymlink != 0:
+		target, err := os.Readlink(src)
+		if err != nil {
+			return err
+		}
+		return os.Symlink(target, dst)
+	case info.IsDir():
+		err = os.MkdirAll(dst, 0o755)
+	default:
+		var f *os.File
+		if f, err = os.OpenFile(dst, os.O_CREATE|os.O_WRONLY, 0o644); err == nil {
+			err = f.Close()
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := unix.Mount(src, dst, "", unix.MS_BIND|unix.MS_REC, ""); err != nil {
+		return fmt.Errorf("bind %s: %w", src, err)
+	}
+	// Bind mounts only take flags on a remount
+	flags := uintptr(unix.MS_REMOUNT | unix.MS_BIND | unix.MS_NOSUID)
+	if readOnly {
+		flags |= unix.MS_RDONLY | unix.MS_NODEV
+	}
+	if err := unix.Mount("", dst, "", flags, ""); err != nil {
+		return fmt.Errorf("restrict %s: %w", src, err)
+	}
+	return nil
+}
+
+func mountFresh(dst, fstype, data string) error {
+	if err := os.MkdirAll(dst, 0o755); err != nil {
+		return err
+	}
+	if err := unix.Mount(fstype, dst, fstype, unix.MS_NOSUID|unix.MS_NODEV|unix.MS_NOEXEC, data); err != nil {
+		return fmt.Errorf("mount %s: %w", dst, err)
+	}
+	return nil
+}
+
+func setLimits(limits sandboxLimits) error {
+	for resource, value := range map[int]uint64{
+		unix.RLIMIT_CPU:   limits.CPUSeconds,
+		unix.RLIMIT_DATA:  limits.MemoryBytes,
+		unix.RLIMIT_FSIZE: limits.FileBytes,
+		unix.RLIMIT_NPROC: limits.Processes,
+		unix.RLIMIT_CORE:  0,
+	} {
+		if err := unix.Setrlimit(resource, &unix.Rlimit{Cur: value, Max: value}); err != nil {
+			return fmt.Errorf("setrlimit %d: %w", resource, err)
+		}
+	}
+	return nil
+}
+
+// dropPrivileges switches to the sandbox user for good: no supplementary
+// groups, no way back through setuid binaries, and still killed along with
+// the service, since changing credentials clears the parent-death signal.
+func dropPrivileges(uid, gid int) error {
+	if uid <= 0 || gid <= 0 {
+		return errors.New("refusing to run code as root")
+	}
+	if err := syscall.Setgroups(nil); err != nil {
+		return fmt.Errorf("setgroups: %w", err)
+	}
+	if err := syscall.Setresgid(gid, gid, gid); err != nil {
+		return fmt.Errorf("setgid: %w", err)
+	}
+	if err := syscall.Setresuid(uid, uid, uid); err != nil {
+		return fmt.Errorf("setuid: %w", err)
+	}
+	if err := unix.Prctl(unix.PR_SET_NO_NEW_PRIVS, 1, 0, 0, 0); err != nil {
+		return fmt.Errorf("no_new_privs: %w", err)
+	}
+	return unix.Prctl(unix.PR_SET_PDEATHSIG, uintptr(unix.SIGKILL), 0, 0, 0)
+}
+
+// killProcessGroup kills the child and anything it forked.
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+}
+
+func peakMemoryKB(state *os.ProcessState) int64 {
+	if usage, ok := state.SysUsage().(*syscall.Rusage); ok {
+		return usage.Maxrss // Already in KB on Linux
+	}
+	return 0
+}
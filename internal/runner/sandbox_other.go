@@ -0,0 +1,34 @@
+//go:build !linux
+
+package runner
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+)
+
+var errNoSandbox = errors.New("sandboxed code execution needs Linux namespaces")
+
+// sandboxAvailable always fails outside Linux: without namespaces code
+// would run with the service's user and files.
+func sandboxAvailable(isolateNetwork bool) error {
+	return errNoSandbox
+}
+
+func configureSandbox(cmd *exec.Cmd, isolateNetwork bool) {}
+
+func enterSandbox(spec *sandboxSpec) error {
+	return errNoSandbox
+}
+
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+	return cmd.Process.Kill()
+}
+
+func peakMemoryKB(state *os.ProcessState) int64 {
+	return 0
+}
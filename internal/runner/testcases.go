@@ -0,0 +1,92 @@
+package runner
+
+import "encoding/json"
+
+// hiddenTestCasesKey holds the test cases of a snapshot the candidate must not see.
+const hiddenTestCasesKey = "hidden_test_cases"
+
+// snapshotCase accepts the field names problem snapshots commonly use.
+type snapshotCase struct {
+	Input          json.RawMessage `json:"input"`
+	Expected       json.RawMessage `json:"expected"`
+	ExpectedOutput json.RawMessage `json:"expected_output"`
+	Output         json.RawMessage `json:"output"`
+}
+
+// TestCasesFromSnapshot extracts runnable test cases from a problem snapshot:
+// its test_cases, else its public examples. Hidden test cases are split off
+// the snapshot when the interview starts and are not looked at here.
+func TestCasesFromSnapshot(snapshot []byte) []TestCase {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(snapshot, &doc); err != nil {
+		return nil
+	}
+
+	for _, key := range []string{"test_cases", "examples"} {
+		raw, ok := doc[key]
+		if !ok {
+			continue
+		}
+		if tests := parseTestCases(raw); len(tests) > 0 {
+			return tests
+		}
+	}
+	return nil
+}
+
+// SplitHiddenTestCases removes the hidden test cases from a problem snapshot
+// and returns them separately. Snapshots without any are returned as is.
+func SplitHiddenTestCases(snapshot []byte) ([]byte, []TestCase) {
+	var doc map[string]json.RawMessage
+	if err := json.Unmarshal(snapshot, &doc); err != nil {
+		return snapshot, nil
+	}
+	raw, ok := doc[hiddenTestCasesKey]
+	if !ok {
+		return snapshot, nil
+	}
+
+	delete(doc, hiddenTestCasesKey)
+	public, err := json.Marshal(doc)
+	if err != nil {
+		return snapshot, nil
+	}
+	return public, parseTestCases(raw)
+}
+
+// parseTestCases decodes a list of snapshot cases, skipping incomplete ones.
+func parseTestCases(raw json.RawMessage) []TestCase {
+	var cases []snapshotCase
+	if err := json.Unmarshal(raw, &cases); err != nil {
+		return nil
+	}
+
+	var tests []TestCase
+	for _, c := range cases {
+		expected := c.Expected
+		if len(expected) == 0 {
+			expected = c.ExpectedOutput
+		}
+		if len(expected) == 0 {
+			expected = c.Output
+		}
+		if len(c.Input) == 0 || len(expected) == 0 {
+			continue
+		}
+		tests = append(tests, TestCase{
+			Input:    rawToString(c.Input),
+			Expected: rawToString(expected),
+		})
+	}
+	return tests
+}
+
+// rawToString keeps strings as-is and renders any other JSON value verbatim,
+// so {"input": [1, 2]} is fed to stdin as "[1, 2]".
+func rawToString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	return string(raw)
+}
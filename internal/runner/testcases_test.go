@@ -0,0 +1,91 @@
+package runner
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+func TestTestCasesFromSnapshot(t *testing.T) {
+	tests := []struct {
+		name     string
+		snapshot string
+		want     []TestCase
+	}{
+		{
+			name:     "test cases",
+			snapshot: `{"test_cases": [{"input": "1 2", "expected": "3"}], "examples": [{"input": "0 0", "output": "0"}]}`,
+			want:     []TestCase{{Input: "1 2", Expected: "3"}},
+		},
+		{
+			name:     "falls back to examples",
+			snapshot: `{"examples": [{"input": "0 0", "output": "0"}, {"input": "2 2", "expected_output": "4"}]}`,
+			want:     []TestCase{{Input: "0 0", Expected: "0"}, {Input: "2 2", Expected: "4"}},
+		},
+		{
+			name:     "empty test cases fall back to examples",
+			snapshot: `{"test_cases": [], "examples": [{"input": "0 0", "output": "0"}]}`,
+			want:     []TestCase{{Input: "0 0", Expected: "0"}},
+		},
+		{
+			name:     "non-string values are kept verbatim",
+			snapshot: `{"test_cases": [{"input": [1, 2], "expected": 3}]}`,
+			want:     []TestCase{{Input: "[1, 2]", Expected: "3"}},
+		},
+		{
+			name:     "incomplete cases are skipped",
+			snapshot: `{"test_cases": [{"input": "1"}, {"expected": "1"}, {"input": "2", "expected": "2"}]}`,
+			want:     []TestCase{{Input: "2", Expected: "2"}},
+		},
+		{
+			name:     "no cases",
+			snapshot: `{"title": "Two Sum"}`,
+		},
+		{
+			name:     "not an object",
+			snapshot: `"Two Sum"`,
+		},
+		{
+			name:     "empty",
+			snapshot: ``,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := TestCasesFromSnapshot([]byte(tt.snapshot)); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("TestCasesFromSnapshot = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSplitHiddenTestCases(t *testing.T) {
+	snapshot := []byte(`{"title": "Two Sum", "examples": [{"input": "0 0", "output": "0"}], "hidden_test_cases": [{"input": "5 5", "expected": "10"}]}`)
+
+	public, hidden := SplitHiddenTestCases(snapshot)
+	if want := []TestCase{{Input: "5 5", Expected: "10"}}; !reflect.DeepEqual(hidden, want) {
+		t.Errorf("hidden = %#v, want %#v", hidden, want)
+	}
+	if bytes.Contains(public, []byte(hiddenTestCasesKey)) {
+		t.Errorf("public snapshot still holds the hidden test cases: %s", public)
+	}
+	if got, want := TestCasesFromSnapshot(public), []TestCase{{Input: "0 0", Expected: "0"}}; !reflect.DeepEqual(got, want) {
+		t.Errorf("public test cases = %#v, want %#v", got, want)
+	}
+}
+
+func TestSplitHiddenTestCasesWithoutHidden(t *testing.T) {
+	for _, snapshot := range []string{`{"title": "Two Sum"}`, `"Two Sum"`, ``} {
+		public, hidden := SplitHiddenTestCases([]byte(snapshot))
+		if string(public) != snapshot || hidden != nil {
+			t.Errorf("SplitHiddenTestCases(%q) = %q, %#v; want the snapshot unchanged", snapshot, public, hidden)
+		}
+	}
+}
+
+func TestHiddenTestCasesAreNotSnapshotCases(t *testing.T) {
+	snapshot := []byte(`{"hidden_test_cases": [{"input": "5 5", "expected": "10"}]}`)
+	if got := TestCasesFromSnapshot(snapshot); got != nil {
+		t.Errorf("TestCasesFromSnapshot = %#v, want no cases", got)
+	}
+}
@@ -0,0 +1,35 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"minos/internal/llm"
+	"minos/internal/ratelimit"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+)
+
+// checkBudget returns the *ratelimit.LimitError of a user whose daily token
+// budget is spent. Budget store failures are logged and let through.
+func checkBudget(budget ratelimit.TokenBudget, userID uuid.UUID) error {
+	if userID == uuid.Nil {
+		return nil
+	}
+	err := budget.Check(context.Background(), userID)
+	if err == nil || errors.Is(err, ratelimit.ErrLimitExceeded) {
+		return err
+	}
+	log.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to check token budget")
+	return nil
+}
+
+// chargeBudget adds the tokens a model call reported to the user's budget.
+func chargeBudget(budget ratelimit.TokenBudget, userID uuid.UUID, resp *llm.Response) {
+	if resp == nil || userID == uuid.Nil {
+		return
+	}
+	if err := budget.Consume(context.Background(), userID, resp.Usage.TotalTokens); err != nil {
+		log.Warn().Err(err).Str("user_id", userID.String()).Msg("Failed to charge token budget")
+	}
+}
@@ -0,0 +1,61 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"minos/internal/llm"
+	"minos/internal/ratelimit"
+
+	"github.com/google/uuid"
+)
+
+// fakeBudget records consumption and returns checkErr from Check.
+type fakeBudget struct {
+	checkErr error
+	consumed map[uuid.UUID]int
+}
+
+func (b *fakeBudget) Check(ctx context.Context, userID uuid.UUID) error {
+	return b.checkErr
+}
+
+func (b *fakeBudget) Consume(ctx context.Context, userID uuid.UUID, tokens int) error {
+	if b.consumed == nil {
+		b.consumed = map[uuid.UUID]int{}
+	}
+	b.consumed[userID] += tokens
+	return nil
+}
+
+func TestCheckBudget(t *testing.T) {
+	limitErr := &ratelimit.LimitError{Reason: "daily budget of 100 tokens used up"}
+
+	if err := checkBudget(&fakeBudget{checkErr: limitErr}, uuid.New()); !errors.Is(err, ratelimit.ErrLimitExceeded) {
+		t.Errorf("checkBudget of a spent budget = %v, want ErrLimitExceeded", err)
+	}
+	if err := checkBudget(&fakeBudget{checkErr: errors.New("connection refused")}, uuid.New()); err != nil {
+		t.Errorf("checkBudget with the store down = %v, want nil", err)
+	}
+	if err := checkBudget(&fakeBudget{checkErr: limitErr}, uuid.Nil); err != nil {
+		t.Errorf("checkBudget without a user = %v, want nil", err)
+	}
+}
+
+func TestChargeBudget(t *testing.T) {
+	budget := &fakeBudget{}
+	userID := uuid.New()
+
+	chargeBudget(budget, userID, &llm.Response{Usage: llm.Usage{PromptTokens: 30, CompletionTokens: 12, TotalTokens: 42}})
+	chargeBudget(budget, userID, &llm.Response{Usage: llm.Usage{TotalTokens: 8}})
+	chargeBudget(budget, userID, nil)
+	chargeBudget(budget, uuid.Nil, &llm.Response{Usage: llm.Usage{TotalTokens: 100}})
+
+	if got := budget.consumed[userID]; got != 50 {
+		t.Errorf("consumed = %d, want 50", got)
+	}
+	if _, ok := budget.consumed[uuid.Nil]; ok {
+		t.Error("tokens were charged without a user")
+	}
+}
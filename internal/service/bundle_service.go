@@ -0,0 +1,527 @@
+package service
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/repository"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/rs/zerolog/log"
+	"gopkg.in/yaml.v3"
+)
+
+// ErrInvalidBundle is returned for bundles that cannot be decoded or whose
+// items fail validation. Nothing is imported from them.
+var ErrInvalidBundle = errors.New("invalid bundle")
+
+// Bundle serialization formats.
+const (
+	BundleFormatYAML = "yaml"
+	BundleFormatJSON = "json"
+)
+
+// BundleService moves prompt templates, their release channels and problems
+// between environments as versioned YAML or JSON bundles.
+type BundleService interface {
+	ExportBundle(query *dto.BundleExportQuery) (*dto.Bundle, error)
+	// ImportBundle works out what importing bundle changes and applies it in
+	// one transaction, unless options ask for a dry run. Channel moves are
+	// recorded in the release history as performed by actor.
+	ImportBundle(bundle *dto.Bundle, options *dto.BundleImportOptions, actor string) (*dto.BundleImportResult, error)
+}
+
+type bundleService struct {
+	prompts  repository.PromptTemplateRepository
+	problems repository.ProblemRepository
+	repo     repository.BundleRepository
+}
+
+func NewBundleService(prompts repository.PromptTemplateRepository, problems repository.ProblemRepository, repo repository.BundleRepository) BundleService {
+	return &bundleService{
+		prompts:  prompts,
+		problems: problems,
+		repo:     repo,
+	}
+}
+
+// EncodeBundle serializes a bundle as YAML or JSON.
+func EncodeBundle(bundle *dto.Bundle, format string) ([]byte, error) {
+	if format == BundleFormatJSON {
+		return json.MarshalIndent(bundle, "", "  ")
+	}
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(bundle); err != nil {
+		return nil, err
+	}
+	if err := enc.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// DecodeBundle parses a YAML or JSON bundle. Unknown fields are rejected so
+// that typos do not silently drop data.
+func DecodeBundle(data []byte, format string) (*dto.Bundle, error) {
+	var bundle dto.Bundle
+	var err error
+	if format == BundleFormatJSON {
+		dec := json.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		err = dec.Decode(&bundle)
+	} else {
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		err = dec.Decode(&bundle)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidBundle, err)
+	}
+	return &bundle, nil
+}
+
+func (s *bundleService) ExportBundle(query *dto.BundleExportQuery) (*dto.Bundle, error) {
+	withPrompts, withProblems, err := BundleSections(query.Include)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now().UTC()
+	bundle := &dto.Bundle{FormatVersion: dto.BundleFormatVersion, ExportedAt: &now}
+	if withPrompts {
+		templates, err := s.prompts.FindAllPromptTemplates(query.PromptName, "", nil)
+		if err != nil {
+			return nil, err
+		}
+		// Oldest version first, so imports create them in their original order
+		sort.Slice(templates, func(i, j int) bool {
+			if templates[i].Name != templates[j].Name {
+				return templates[i].Name < templates[j].Name
+			}
+			return templates[i].ID < templates[j].ID
+		})
+		for i := range templates {
+			bundle.Prompts = append(bundle.Prompts, toBundlePrompt(&templates[i]))
+		}
+
+		channels, err := s.prompts.FindPromptChannels(query.PromptName)
+		if err != nil {
+			return nil, err
+		}
+		for _, pc := range channels {
+			bundle.Channels = append(bundle.Channels, dto.BundleChannel{Name: pc.Name, Channel: pc.Channel, Version: pc.Version})
+		}
+	}
+	if withProblems {
+		problems, err := s.problems.FindProblems(repository.ProblemFilter{})
+		if err != nil {
+			return nil, err
+		}
+		sort.Slice(problems, func(i, j int) bool { return problems[i].Slug < problems[j].Slug })
+		for i := range problems {
+			bundle.Problems = append(bundle.Problems, toBundleProblem(&problems[i]))
+		}
+	}
+	return bundle, nil
+}
+
+// BundleSections parses the comma-separated sections of an export, both
+// when include is empty.
+func BundleSections(include string) (prompts, problems bool, err error) {
+	if strings.TrimSpace(include) == "" {
+		return true, true, nil
+	}
+	for _, section := range strings.Split(include, ",") {
+		switch strings.TrimSpace(section) {
+		case "prompts":
+			prompts = true
+		case "problems":
+			problems = true
+		default:
+			return false, false, fmt.Errorf("%w: unknown section '%s', expected prompts or problems", ErrInvalidQuery, section)
+		}
+	}
+	return prompts, problems, nil
+}
+
+func (s *bundleService) ImportBundle(bundle *dto.Bundle, options *dto.BundleImportOptions, actor string) (*dto.BundleImportResult, error) {
+	policy := options.Policy
+	if policy == "" {
+		policy = dto.BundlePolicySkip
+	}
+	switch policy {
+	case dto.BundlePolicySkip, dto.BundlePolicyOverwrite, dto.BundlePolicyNewVersion:
+	default:
+		return nil, fmt.Errorf("%w: unknown conflict policy '%s'", ErrInvalidBundle, policy)
+	}
+	if bundle.FormatVersion != dto.BundleFormatVersion {
+		return nil, fmt.Errorf("%w: unsupported format_version %d, expected %d", ErrInvalidBundle, bundle.FormatVersion, dto.BundleFormatVersion)
+	}
+
+	plan := &bundlePlan{policy: policy}
+	if err := s.planPrompts(plan, bundle); err != nil {
+		return nil, err
+	}
+	if err := s.planChannels(plan, bundle); err != nil {
+		return nil, err
+	}
+	if err := s.planProblems(plan, bundle); err != nil {
+		return nil, err
+	}
+
+	result := &dto.BundleImportResult{
+		DryRun:  options.DryRun,
+		Policy:  policy,
+		Changes: plan.changes,
+		Summary: map[string]int{},
+	}
+	if result.Changes == nil {
+		result.Changes = []dto.BundleChange{}
+	}
+	for _, change := range plan.changes {
+		result.Summary[change.Action]++
+	}
+	if options.DryRun || plan.writes.Empty() {
+		return result, nil
+	}
+
+	if err := s.repo.ApplyBundle(&plan.writes, actor); err != nil {
+		return nil, err
+	}
+	log.Info().Str("policy", policy).Str("actor", actor).Interface("summary", result.Summary).Msg("Bundle imported")
+	return result, nil
+}
+
+// bundlePlan is what importing a bundle does, worked out against the stored items.
+type bundlePlan struct {
+	policy  string
+	writes  repository.BundleChanges
+	changes []dto.BundleChange
+
+	// Templates by name and version as they will be after the import
+	versions map[string]map[string]*model.PromptTemplate
+	// Versions the new-version policy imported bundle versions under, by name@version
+	renamed map[string]string
+}
+
+func (s *bundleService) planPrompts(plan *bundlePlan, bundle *dto.Bundle) error {
+	stored, err := s.prompts.FindAllPromptTemplates("", "", nil)
+	if err != nil {
+		return err
+	}
+	plan.versions = map[string]map[string]*model.PromptTemplate{}
+	plan.renamed = map[string]string{}
+	for i := range stored {
+		t := &stored[i]
+		if plan.versions[t.Name] == nil {
+			plan.versions[t.Name] = map[string]*model.PromptTemplate{}
+		}
+		plan.versions[t.Name][t.Version] = t
+	}
+
+	inBundle := map[string]bool{}
+	for i, p := range bundle.Prompts {
+		if p.Name == "" || p.Version == "" || p.Content == "" {
+			return fmt.Errorf("%w: prompt %d needs a name, version and content", ErrInvalidBundle, i+1)
+		}
+		key := p.Name + "@" + p.Version
+		if inBundle[key] {
+			return fmt.Errorf("%w: prompt %s appears more than once", ErrInvalidBundle, key)
+		}
+		inBundle[key] = true
+		if err := validatePromptTemplate(p.Content, p.Variables); err != nil {
+			return fmt.Errorf("%w: prompt %s: %w", ErrInvalidBundle, key, err)
+		}
+	}
+
+	for _, p := range bundle.Prompts {
+		key := p.Name + "@" + p.Version
+		imported := &model.PromptTemplate{
+			Name:        p.Name,
+			Version:     p.Version,
+			Description: p.Description,
+			Content:     p.Content,
+			Variables:   p.Variables,
+			IsActive:    p.IsActive == nil || *p.IsActive,
+		}
+		if plan.versions[p.Name] == nil {
+			plan.versions[p.Name] = map[string]*model.PromptTemplate{}
+		}
+		existing := plan.versions[p.Name][p.Version]
+		change := dto.BundleChange{Kind: "prompt", Key: key}
+
+		if existing == nil {
+			change.Action = dto.BundleActionCreate
+			plan.writes.CreatePrompts = append(plan.writes.CreatePrompts, imported)
+			plan.versions[p.Name][p.Version] = imported
+			plan.changes = append(plan.changes, change)
+			continue
+		}
+
+		change.Fields = promptFields(existing, imported)
+		switch {
+		case len(change.Fields) == 0:
+			change.Action = dto.BundleActionUnchanged
+		case plan.policy == dto.BundlePolicySkip:
+			change.Action = dto.BundleActionSkip
+		case plan.policy == dto.BundlePolicyOverwrite:
+			change.Action = dto.BundleActionUpdate
+			updated := *existing
+			updated.Description = imported.Description
+			updated.Content = imported.Content
+			updated.Variables = imported.Variables
+			updated.IsActive = imported.IsActive
+			plan.writes.UpdatePrompts = append(plan.writes.UpdatePrompts, &updated)
+			plan.versions[p.Name][p.Version] = &updated
+		default:
+			versions := plan.versions[p.Name]
+			imported.Version = nextFree(p.Version, func(version string) bool {
+				return versions[version] != nil || inBundle[p.Name+"@"+version]
+			})
+			change.Action = dto.BundleActionNewVersion
+			change.Target = imported.Version
+			plan.writes.CreatePrompts = append(plan.writes.CreatePrompts, imported)
+			versions[imported.Version] = imported
+			plan.renamed[key] = imported.Version
+		}
+		plan.changes = append(plan.changes, change)
+	}
+	return nil
+}
+
+func (s *bundleService) planChannels(plan *bundlePlan, bundle *dto.Bundle) error {
+	stored, err := s.prompts.FindPromptChannels("")
+	if err != nil {
+		return err
+	}
+	current := map[string]*model.PromptChannel{}
+	for i := range stored {
+		current[stored[i].Name+"/"+stored[i].Channel] = &stored[i]
+	}
+
+	seen := map[string]bool{}
+	for i, c := range bundle.Channels {
+		if c.Name == "" || c.Channel == "" || c.Version == "" {
+			return fmt.Errorf("%w: channel %d needs a name, channel and version", ErrInvalidBundle, i+1)
+		}
+		key := c.Name + "/" + c.Channel
+		if seen[key] {
+			return fmt.Errorf("%w: channel %s appears more than once", ErrInvalidBundle, key)
+		}
+		seen[key] = true
+		if err := validateChannel(c.Channel); err != nil {
+			return fmt.Errorf("%w: %w", ErrInvalidBundle, err)
+		}
+
+		version := c.Version
+		if renamed, ok := plan.renamed[c.Name+"@"+c.Version]; ok {
+			version = renamed
+		}
+		target := plan.versions[c.Name][version]
+		if target == nil {
+			return fmt.Errorf("%w: channel %s points at version '%s', which is neither stored nor in the bundle", ErrInvalidBundle, key, c.Version)
+		}
+		if !target.IsActive {
+			return fmt.Errorf("%w: channel %s points at version '%s', which is inactive", ErrInvalidBundle, key, version)
+		}
+
+		change := dto.BundleChange{Kind: "channel", Key: key, Target: version}
+		pc := current[key]
+		switch {
+		case pc == nil:
+			change.Action = dto.BundleActionCreate
+		case pc.Version == version:
+			change.Action = dto.BundleActionUnchanged
+			change.From = pc.Version
+		case plan.policy == dto.BundlePolicySkip:
+			change.Action = dto.BundleActionSkip
+			change.From = pc.Version
+		default:
+			change.Action = dto.BundleActionUpdate
+			change.From = pc.Version
+		}
+		if change.Action == dto.BundleActionCreate || change.Action == dto.BundleActionUpdate {
+			plan.writes.MoveChannels = append(plan.writes.MoveChannels, repository.BundleChannelMove{Name: c.Name, Channel: c.Channel, Version: version})
+		}
+		plan.changes = append(plan.changes, change)
+	}
+	return nil
+}
+
+func (s *bundleService) planProblems(plan *bundlePlan, bundle *dto.Bundle) error {
+	stored, err := s.problems.FindProblems(repository.ProblemFilter{})
+	if err != nil {
+		return err
+	}
+	bySlug := map[string]*model.Problem{}
+	for i := range stored {
+		bySlug[stored[i].Slug] = &stored[i]
+	}
+
+	inBundle := map[string]bool{}
+	for i, p := range bundle.Problems {
+		if p.Slug == "" || p.Title == "" || p.Statement == "" {
+			return fmt.Errorf("%w: problem %d needs a slug, title and statement", ErrInvalidBundle, i+1)
+		}
+		switch p.Difficulty {
+		case model.ProblemDifficultyEasy, model.ProblemDifficultyMedium, model.ProblemDifficultyHard:
+		default:
+			return fmt.Errorf("%w: problem %s has difficulty '%s', expected easy, medium or hard", ErrInvalidBundle, p.Slug, p.Difficulty)
+		}
+		if inBundle[p.Slug] {
+			return fmt.Errorf("%w: problem %s appears more than once", ErrInvalidBundle, p.Slug)
+		}
+		inBundle[p.Slug] = true
+	}
+
+	for _, p := range bundle.Problems {
+		imported := fromBundleProblem(&p)
+		existing := bySlug[p.Slug]
+		change := dto.BundleChange{Kind: "problem", Key: p.Slug}
+
+		if existing == nil {
+			change.Action = dto.BundleActionCreate
+			plan.writes.CreateProblems = append(plan.writes.CreateProblems, imported)
+			bySlug[p.Slug] = imported
+			plan.changes = append(plan.changes, change)
+			continue
+		}
+
+		change.Fields = problemFields(existing, imported)
+		switch {
+		case len(change.Fields) == 0:
+			change.Action = dto.BundleActionUnchanged
+		case plan.policy == dto.BundlePolicySkip:
+			change.Action = dto.BundleActionSkip
+		case plan.policy == dto.BundlePolicyOverwrite:
+			change.Action = dto.BundleActionUpdate
+			imported.ID = existing.ID
+			imported.CreatedAt = existing.CreatedAt
+			plan.writes.UpdateProblems = append(plan.writes.UpdateProblems, imported)
+		default:
+			imported.Slug = nextFree(p.Slug, func(slug string) bool {
+				return bySlug[slug] != nil || inBundle[slug]
+			})
+			change.Action = dto.BundleActionNewVersion
+			change.Target = imported.Slug
+			plan.writes.CreateProblems = append(plan.writes.CreateProblems, imported)
+			bySlug[imported.Slug] = imported
+		}
+		plan.changes = append(plan.changes, change)
+	}
+	return nil
+}
+
+// nextFree returns base suffixed with the lowest number from 2 up that is not taken.
+func nextFree(base string, taken func(string) bool) string {
+	for n := 2; ; n++ {
+		if candidate := fmt.Sprintf("%s-%d", base, n); !taken(candidate) {
+			return candidate
+		}
+	}
+}
+
+// promptFields lists the fields of imported that differ from stored.
+func promptFields(stored, imported *model.PromptTemplate) []string {
+	var fields []string
+	if stored.Description != imported.Description {
+		fields = append(fields, "description")
+	}
+	if stored.Content != imported.Content {
+		fields = append(fields, "content")
+	}
+	if stored.Variables != imported.Variables {
+		fields = append(fields, "variables")
+	}
+	if stored.IsActive != imported.IsActive {
+		fields = append(fields, "is_active")
+	}
+	return fields
+}
+
+// problemFields lists the fields of imported that differ from stored.
+func problemFields(stored, imported *model.Problem) []string {
+	a, b := toBundleProblem(stored), toBundleProblem(imported)
+	var fields []string
+	for _, field := range []struct {
+		name string
+		a, b interface{}
+	}{
+		{"title", a.Title, b.Title},
+		{"statement", a.Statement, b.Statement},
+		{"difficulty", a.Difficulty, b.Difficulty},
+		{"tags", a.Tags, b.Tags},
+		{"constraints", a.Constraints, b.Constraints},
+		{"examples", a.Examples, b.Examples},
+		{"hidden_test_cases", a.HiddenTestCases, b.HiddenTestCases},
+		{"reference_solution", a.ReferenceSolution, b.ReferenceSolution},
+		{"reference_language", a.ReferenceLanguage, b.ReferenceLanguage},
+		{"editorial", a.Editorial, b.Editorial},
+	} {
+		if !reflect.DeepEqual(field.a, field.b) {
+			fields = append(fields, field.name)
+		}
+	}
+	return fields
+}
+
+func toBundlePrompt(t *model.PromptTemplate) dto.BundlePrompt {
+	active := t.IsActive
+	return dto.BundlePrompt{
+		Name:        t.Name,
+		Version:     t.Version,
+		Description: t.Description,
+		Content:     t.Content,
+		Variables:   t.Variables,
+		IsActive:    &active,
+	}
+}
+
+func toBundleProblem(p *model.Problem) dto.BundleProblem {
+	return dto.BundleProblem{
+		Slug:              p.Slug,
+		Title:             p.Title,
+		Statement:         p.Statement,
+		Difficulty:        p.Difficulty,
+		Tags:              nilIfEmpty(p.Tags),
+		Constraints:       nilIfEmpty(p.Constraints),
+		Examples:          nilIfEmpty(p.Examples),
+		HiddenTestCases:   nilIfEmpty(p.HiddenTestCases),
+		ReferenceSolution: p.ReferenceSolution,
+		ReferenceLanguage: p.ReferenceLanguage,
+		Editorial:         p.Editorial,
+	}
+}
+
+func fromBundleProblem(p *dto.BundleProblem) *model.Problem {
+	return &model.Problem{
+		Slug:              p.Slug,
+		Title:             p.Title,
+		Statement:         p.Statement,
+		Difficulty:        p.Difficulty,
+		Tags:              p.Tags,
+		Constraints:       p.Constraints,
+		Examples:          p.Examples,
+		HiddenTestCases:   p.HiddenTestCases,
+		ReferenceSolution: p.ReferenceSolution,
+		ReferenceLanguage: p.ReferenceLanguage,
+		Editorial:         p.Editorial,
+	}
+}
+
+// nilIfEmpty treats empty and missing lists alike, so they compare equal.
+func nilIfEmpty[T any](s []T) []T {
+	if len(s) == 0 {
+		return nil
+	}
+	return s
+}
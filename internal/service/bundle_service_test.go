@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"minos/internal/model"
+)
+
+func TestBundleSections(t *testing.T) {
+	tests := []struct {
+		include           string
+		prompts, problems bool
+	}{
+		{"", true, true},
+		{"  ", true, true},
+		{"prompts", true, false},
+		{"problems", false, true},
+		{"prompts, problems", true, true},
+		{"problems,problems", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.include, func(t *testing.T) {
+			prompts, problems, err := BundleSections(tt.include)
+			if err != nil {
+				t.Fatalf("BundleSections(%q): %v", tt.include, err)
+			}
+			if prompts != tt.prompts || problems != tt.problems {
+				t.Errorf("BundleSections(%q) = %v, %v; want %v, %v", tt.include, prompts, problems, tt.prompts, tt.problems)
+			}
+		})
+	}
+}
+
+func TestBundleSectionsRejectsUnknown(t *testing.T) {
+	for _, include := range []string{"experiments", "prompts,", "prompts,keys"} {
+		if _, _, err := BundleSections(include); !errors.Is(err, ErrInvalidQuery) {
+			t.Errorf("BundleSections(%q) error = %v, want ErrInvalidQuery", include, err)
+		}
+	}
+}
+
+func TestNextFree(t *testing.T) {
+	taken := map[string]bool{"two-sum-2": true, "two-sum-3": true, "two-sum-5": true}
+	isTaken := func(s string) bool { return taken[s] }
+
+	if got := nextFree("two-sum", isTaken); got != "two-sum-4" {
+		t.Errorf("nextFree = %q, want two-sum-4", got)
+	}
+	if got := nextFree("valid-parentheses", isTaken); got != "valid-parentheses-2" {
+		t.Errorf("nextFree = %q, want valid-parentheses-2", got)
+	}
+}
+
+func TestPromptFields(t *testing.T) {
+	stored := &model.PromptTemplate{Name: "interviewer", Version: "1.0.0", Content: "a", Variables: "{}", IsActive: true}
+	imported := *stored
+	if fields := promptFields(stored, &imported); len(fields) != 0 {
+		t.Errorf("promptFields of identical templates = %v, want none", fields)
+	}
+
+	imported.Content = "b"
+	imported.IsActive = false
+	if fields, want := promptFields(stored, &imported), []string{"content", "is_active"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("promptFields = %v, want %v", fields, want)
+	}
+}
+
+func TestProblemFields(t *testing.T) {
+	stored := &model.Problem{Slug: "two-sum", Title: "Two Sum", Tags: []string{}}
+	imported := &model.Problem{Slug: "two-sum", Title: "Two Sum"}
+	if fields := problemFields(stored, imported); len(fields) != 0 {
+		t.Errorf("problemFields with empty and missing tags = %v, want none", fields)
+	}
+
+	imported.Tags = []string{"array"}
+	imported.HiddenTestCases = []model.ProblemTestCase{{Input: "1", Expected: "1"}}
+	if fields, want := problemFields(stored, imported), []string{"tags", "hidden_test_cases"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("problemFields = %v, want %v", fields, want)
+	}
+}
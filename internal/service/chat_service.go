@@ -2,139 +2,397 @@ package service
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"minos/internal/cache"
 	"minos/internal/dto"
 	"minos/internal/llm"
-	"minos/internal/llm/gemini"
 	"minos/internal/model"
+	"minos/internal/ratelimit"
 	"minos/internal/repository"
+	"time"
 
-	"github.com/google/generative-ai-go/genai"
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 )
 
+// ErrNoHintsLeft is returned by RequestHint once every hint level was given.
+var ErrNoHintsLeft = errors.New("no hints left")
+
 type ChatService interface {
+	// Greet generates the interviewer's opening message for a new interview
+	// through the same chat path as later turns and saves it.
+	Greet(interviewID uuid.UUID) (*model.Message, error)
 	SendMessage(interviewID uuid.UUID, req *dto.SendMessageRequest) (*dto.SendMessageResponse, error)
+	SendMessageStream(ctx context.Context, interviewID uuid.UUID, req *dto.SendMessageRequest, onDelta llm.DeltaFunc) (*dto.SendMessageResponse, error)
+	// RequestHint gives the candidate the hint at the next level and counts
+	// it against the interview.
+	RequestHint(interviewID uuid.UUID) (*dto.HintResponse, error)
 	GetHistory(interviewID uuid.UUID) ([]model.Message, error)
+	GetHistorySince(interviewID uuid.UUID, lastMessageID uuid.UUID) ([]model.Message, error)
 }
 
 type chatService struct {
 	msgRepo       repository.MessageRepository
 	interviewRepo repository.InterviewRepository
-	geminiClient  *gemini.Client
+	llmProvider   llm.Provider
+	prompts       PromptResolver
+	sessions      cache.SessionCache
+	history       HistoryManager
+	budget        ratelimit.TokenBudget
+	prices        llm.PriceTable
 }
 
-func NewChatService(msgRepo repository.MessageRepository, interviewRepo repository.InterviewRepository, geminiClient *gemini.Client) ChatService {
+func NewChatService(msgRepo repository.MessageRepository, interviewRepo repository.InterviewRepository, llmProvider llm.Provider, prompts PromptResolver, sessions cache.SessionCache, history HistoryManager, budget ratelimit.TokenBudget, prices llm.PriceTable) ChatService {
 	return &chatService{
 		msgRepo:       msgRepo,
 		interviewRepo: interviewRepo,
-		geminiClient:  geminiClient,
+		llmProvider:   llmProvider,
+		prompts:       prompts,
+		sessions:      sessions,
+		history:       history,
+		budget:        budget,
+		prices:        prices,
 	}
 }
 
-func (s *chatService) SendMessage(interviewID uuid.UUID, req *dto.SendMessageRequest) (*dto.SendMessageResponse, error) {
-	// 1. Validate Interview
-	interview, err := s.interviewRepo.FindInterviewByID(interviewID)
+// greetingKickoff asks the interviewer for its opening message. It is not
+// stored; the greeting is the first message of the conversation.
+const greetingKickoff = "Please start the interview by greeting the candidate and asking them to explain their initial thought process."
+
+// defaultGreeting opens the interview when the interviewer cannot.
+const defaultGreeting = "Hello! I'm ready to help you with this problem. How would you like to start?"
+
+func (s *chatService) Greet(interviewID uuid.UUID) (*model.Message, error) {
+	session, instruction, err := s.activeSession(interviewID)
 	if err != nil {
 		return nil, err
 	}
-	if interview.Status != model.InterviewStatusActive {
-		return nil, fmt.Errorf("interview is not active")
+	conversation, err := s.history.Build(session, instruction, greetingKickoff)
+	if err != nil {
+		return nil, err
 	}
 
-	// 2. Prepare User Content
-	userContent := req.Content
-	if req.Code != "" {
-		lang := req.Language
-		if lang == "" {
-			lang = "unknown"
+	msg := &model.Message{
+		InterviewID: interviewID,
+		Role:        model.MessageRoleAssistant,
+		Content:     defaultGreeting,
+	}
+	// A user out of budget still gets greeted, just not by the model
+	if err := checkBudget(s.budget, session.UserID); err != nil {
+		log.Info().Err(err).Str("interview_id", interviewID.String()).Msg("Greeting with the default, token budget spent")
+	} else {
+		resp, err := s.llmProvider.Chat(context.Background(), conversation.SystemInstruction, conversation.History, greetingKickoff)
+		chargeBudget(s.budget, session.UserID, resp)
+		msg.TokenUsage = tokenUsage(s.prices, resp)
+		if err == nil {
+			msg.Content = resp.Text
+			msg.Model = resp.Model
+		} else {
+			log.Warn().Err(err).Str("interview_id", interviewID.String()).Msg("Failed to generate greeting")
 		}
-		userContent += fmt.Sprintf("\n\n[USER ATTACHED CODE (%s)]:\n```%s\n%s\n```\n(Please review this code as part of the interview context)", lang, lang, req.Code)
 	}
+	if err := s.msgRepo.CreateMessage(msg); err != nil {
+		return nil, err
+	}
+	s.remember(interviewID, msg)
+	return msg, nil
+}
 
-	// 3. Save User Message
-	userMsg := &model.Message{
+func (s *chatService) SendMessage(interviewID uuid.UUID, req *dto.SendMessageRequest) (*dto.SendMessageResponse, error) {
+	userID, userContent, conversation, err := s.prepareTurn(interviewID, req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 5. Get Response
+	resp, err := s.llmProvider.Chat(context.Background(), conversation.SystemInstruction, conversation.History, userContent)
+	if err != nil {
+		return nil, err
+	}
+	chargeBudget(s.budget, userID, resp)
+	aiText := resp.Text
+
+	// 6. Save AI Message
+	aiMsg := &model.Message{
 		InterviewID: interviewID,
-		Role:        model.MessageRoleUser,
-		Content:     userContent, // Save full content including attached code
+		Role:        model.MessageRoleAssistant,
+		Content:     aiText,
+		Model:       resp.Model,
+		TokenUsage:  tokenUsage(s.prices, resp),
 	}
-	if err := s.msgRepo.CreateMessage(userMsg); err != nil {
+	if err := s.msgRepo.CreateMessage(aiMsg); err != nil {
 		return nil, err
 	}
+	s.remember(interviewID, aiMsg)
+
+	return &dto.SendMessageResponse{
+		MessageID:  aiMsg.ID,
+		AIResponse: aiText,
+	}, nil
+}
 
-	// 4. Build History for Gemini
-	history, err := s.msgRepo.FindMessagesByInterviewID(interviewID)
+// SendMessageStream streams the AI reply through onDelta and persists it once the
+// stream completes. If the stream breaks midway (e.g. the client disconnects),
+// the partial reply is persisted and marked as truncated.
+func (s *chatService) SendMessageStream(ctx context.Context, interviewID uuid.UUID, req *dto.SendMessageRequest, onDelta llm.DeltaFunc) (*dto.SendMessageResponse, error) {
+	userID, userContent, conversation, err := s.prepareTurn(interviewID, req)
 	if err != nil {
 		return nil, err
 	}
 
-	var geminiHistory []*genai.Content
-
-	// Prepend System Prompt logic
-	systemInstruction := fmt.Sprintf(llm.SystemPromptInterviewer, string(interview.ProblemSnapshot))
+	// 5. Stream Response
+	resp, streamErr := s.llmProvider.ChatStream(ctx, conversation.SystemInstruction, conversation.History, userContent, onDelta)
+	if resp == nil {
+		return nil, streamErr
+	}
+	chargeBudget(s.budget, userID, resp)
 
-	// We construct the chat history for context
-	for _, msg := range history {
-		// Skip the current user message we just saved, as we will send it in SendMessage
-		if msg.ID == userMsg.ID {
-			continue
-		}
-		role := "user"
-		if msg.Role == model.MessageRoleAssistant {
-			role = "model"
-		}
-		geminiHistory = append(geminiHistory, &genai.Content{
-			Role:  role,
-			Parts: []genai.Part{genai.Text(msg.Content)},
-		})
+	// 6. Save AI Message (partial if the stream was cut short)
+	aiMsg := &model.Message{
+		InterviewID: interviewID,
+		Role:        model.MessageRoleAssistant,
+		Content:     resp.Text,
+		Model:       resp.Model,
+		Truncated:   streamErr != nil,
+		TokenUsage:  tokenUsage(s.prices, resp),
+	}
+	if err := s.msgRepo.CreateMessage(aiMsg); err != nil {
+		return nil, err
+	}
+	s.remember(interviewID, aiMsg)
+	if streamErr != nil {
+		return nil, streamErr
 	}
 
-	// Prepend system prompt as fake turn
-	fullHistory := []*genai.Content{
-		{
-			Role:  "user",
-			Parts: []genai.Part{genai.Text(systemInstruction)},
-		},
-		{
-			Role:  "model",
-			Parts: []genai.Part{genai.Text("Understood. I am ready to conduct the interview.")},
-		},
+	return &dto.SendMessageResponse{
+		MessageID:  aiMsg.ID,
+		AIResponse: resp.Text,
+	}, nil
+}
+
+// hintGuidance tells the interviewer how much a hint at each level may give away.
+var hintGuidance = map[model.HintLevel]string{
+	model.HintLevelNudge:      "Give a gentle nudge: ask a guiding question or point at what to look at, without naming the technique or data structure to use.",
+	model.HintLevelApproach:   "Outline the approach: name the key idea or data structure and why it fits, without writing code or pseudo-code.",
+	model.HintLevelPseudoCode: "Walk through the solution in short pseudo-code and leave the implementation in their language to the candidate.",
+}
+
+func (s *chatService) RequestHint(interviewID uuid.UUID) (*dto.HintResponse, error) {
+	session, instruction, err := s.activeSession(interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBudget(s.budget, session.UserID); err != nil {
+		return nil, err
 	}
-	fullHistory = append(fullHistory, geminiHistory...)
 
-	cs := s.geminiClient.StartChat(fullHistory)
-	resp, err := cs.SendMessage(context.Background(), genai.Text(userContent))
+	// 1. Claim the next level, so concurrent requests never get the same one
+	claimed, err := s.interviewRepo.ClaimHint(interviewID, int(model.MaxHintLevel))
 	if err != nil {
 		return nil, err
 	}
+	if claimed == 0 {
+		return nil, ErrNoHintsLeft
+	}
+	level := model.HintLevel(claimed)
+	request := fmt.Sprintf("I'm stuck and would like a hint. [Hint %d of %d, level: %s] %s", level, model.MaxHintLevel, level, hintGuidance[level])
 
-	// 5. Extract Response
-	if len(resp.Candidates) == 0 || len(resp.Candidates[0].Content.Parts) == 0 {
-		return nil, fmt.Errorf("empty response from AI")
+	// 2. Ask the interviewer for it, giving the level back if that fails
+	conversation, err := s.history.Build(session, instruction, request)
+	var resp *llm.Response
+	if err == nil {
+		resp, err = s.llmProvider.Chat(context.Background(), conversation.SystemInstruction, conversation.History, request)
+		chargeBudget(s.budget, session.UserID, resp)
 	}
-	aiText := ""
-	for _, part := range resp.Candidates[0].Content.Parts {
-		if txt, ok := part.(genai.Text); ok {
-			aiText += string(txt)
+	if err != nil {
+		if releaseErr := s.interviewRepo.ReleaseHint(interviewID, claimed); releaseErr != nil {
+			log.Warn().Err(releaseErr).Str("interview_id", interviewID.String()).Msg("Failed to release hint")
 		}
+		return nil, err
 	}
 
-	// 6. Save AI Message
-	aiMsg := &model.Message{
+	// 3. Save the request and the hint, both tagged with the level
+	requestMsg := &model.Message{
+		InterviewID: interviewID,
+		Role:        model.MessageRoleUser,
+		Content:     request,
+		HintLevel:   level,
+	}
+	if err := s.msgRepo.CreateMessage(requestMsg); err != nil {
+		return nil, err
+	}
+	hintMsg := &model.Message{
 		InterviewID: interviewID,
 		Role:        model.MessageRoleAssistant,
-		Content:     aiText,
+		Content:     resp.Text,
+		Model:       resp.Model,
+		HintLevel:   level,
+		TokenUsage:  tokenUsage(s.prices, resp),
 	}
-	if err := s.msgRepo.CreateMessage(aiMsg); err != nil {
+	if err := s.msgRepo.CreateMessage(hintMsg); err != nil {
 		return nil, err
 	}
+	s.remember(interviewID, requestMsg, hintMsg)
 
-	return &dto.SendMessageResponse{
-		MessageID:  aiMsg.ID,
-		AIResponse: aiText,
+	return &dto.HintResponse{
+		MessageID: hintMsg.ID,
+		Level:     int(level),
+		LevelName: level.String(),
+		Hint:      resp.Text,
+		HintsLeft: int(model.MaxHintLevel - level),
 	}, nil
 }
 
+// prepareTurn validates the interview, saves the user message and returns the
+// interview's user and the content to send along with the conversation that
+// precedes it.
+func (s *chatService) prepareTurn(interviewID uuid.UUID, req *dto.SendMessageRequest) (uuid.UUID, string, *ConversationContext, error) {
+	// 1. Validate Interview
+	session, systemInstruction, err := s.activeSession(interviewID)
+	if err != nil {
+		return uuid.Nil, "", nil, err
+	}
+	if err := checkBudget(s.budget, session.UserID); err != nil {
+		return uuid.Nil, "", nil, err
+	}
+
+	// 2. Prepare User Content
+	userContent := req.Content
+	if req.Code != "" {
+		userContent = withAttachedCode(userContent, req.Language, req.Code)
+	}
+
+	// 3. Fit the turns before this message into the context window
+	conversation, err := s.history.Build(session, systemInstruction, userContent)
+	if err != nil {
+		return uuid.Nil, "", nil, err
+	}
+
+	// 4. Save User Message
+	userMsg := &model.Message{
+		InterviewID: interviewID,
+		Role:        model.MessageRoleUser,
+		Content:     userContent, // Save full content including attached code
+	}
+	if err := s.msgRepo.CreateMessage(userMsg); err != nil {
+		return uuid.Nil, "", nil, err
+	}
+	s.remember(interviewID, userMsg)
+
+	return session.UserID, userContent, conversation, nil
+}
+
+// activeSession returns the session of an interview that accepts turns along
+// with the interviewer's system instruction for the next one.
+func (s *chatService) activeSession(interviewID uuid.UUID) (*cache.Session, string, error) {
+	session, err := s.session(interviewID)
+	if err != nil {
+		return nil, "", err
+	}
+	if session.Status != model.InterviewStatusActive {
+		return nil, "", fmt.Errorf("%w: interview is not active", ErrInvalidInterviewState)
+	}
+	instruction := session.SystemPrompt
+	if remaining, timed := remainingTime(session.DurationMinutes, session.ActiveSeconds, session.ResumedAt, time.Now()); timed {
+		if remaining <= 0 {
+			return nil, "", fmt.Errorf("%w: interview time is up", ErrInvalidInterviewState)
+		}
+		instruction += timeRemainingNote(remaining)
+	}
+	return session, instruction, nil
+}
+
+// session returns the cached session of an interview, rebuilding it from
+// Postgres on a miss. Only active interviews are cached.
+func (s *chatService) session(interviewID uuid.UUID) (*cache.Session, error) {
+	ctx := context.Background()
+	session, err := s.sessions.Get(ctx, interviewID)
+	if err != nil {
+		log.Warn().Err(err).Str("interview_id", interviewID.String()).Msg("Failed to read session cache")
+	}
+	if session != nil {
+		return session, nil
+	}
+
+	interview, err := s.interviewRepo.FindInterviewByID(interviewID)
+	if err != nil {
+		return nil, err
+	}
+	session = &cache.Session{
+		InterviewID:      interview.ID,
+		UserID:           interview.UserID,
+		Status:           interview.Status,
+		PromptTemplateID: interview.PromptTemplateID,
+		ProblemSnapshot:  json.RawMessage(interview.ProblemSnapshot),
+		DurationMinutes:  interview.DurationMinutes,
+		ActiveSeconds:    interview.ActiveSeconds,
+		ResumedAt:        interview.ResumedAt,
+
+		TranscriptSummary: interview.TranscriptSummary,
+		SummarizedThrough: interview.SummarizedThrough,
+		PreservedCode:     interview.PreservedCode,
+	}
+	if interview.Status != model.InterviewStatusActive {
+		return session, nil
+	}
+
+	// Render the template the interview started with
+	interviewerPrompt, err := s.prompts.ResolvePinned(llm.PromptNameInterviewer, interview.PromptTemplateID)
+	if err != nil {
+		return nil, err
+	}
+	session.SystemPrompt, err = interviewerPrompt.Render(map[string]interface{}{
+		"problem": string(interview.ProblemSnapshot),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	history, err := s.msgRepo.FindMessagesByInterviewID(interviewID, model.ConversationRoles...)
+	if err != nil {
+		return nil, err
+	}
+	for i := range history {
+		session.Turns = append(session.Turns, cache.TurnFromMessage(&history[i]))
+	}
+
+	if err := s.sessions.Set(ctx, session); err != nil {
+		log.Warn().Err(err).Str("interview_id", interviewID.String()).Msg("Failed to cache session")
+	}
+	return session, nil
+}
+
+// timeRemainingNote tells the interviewer how much of the time budget is
+// left so it can pace the interview.
+func timeRemainingNote(remaining time.Duration) string {
+	minutes := int(remaining.Round(time.Minute) / time.Minute)
+	if minutes < 1 {
+		return "\n\nTime remaining in this interview: less than a minute. Wrap up now."
+	}
+	note := fmt.Sprintf("\n\nTime remaining in this interview: %d minutes.", minutes)
+	if minutes <= 5 {
+		note += " Start wrapping up and make sure the candidate has presented a solution."
+	}
+	return note
+}
+
+// remember writes new messages through to the cached session.
+func (s *chatService) remember(interviewID uuid.UUID, msgs ...*model.Message) {
+	turns := make([]cache.Turn, 0, len(msgs))
+	for _, msg := range msgs {
+		turns = append(turns, cache.TurnFromMessage(msg))
+	}
+	if err := s.sessions.AppendTurns(context.Background(), interviewID, turns...); err != nil {
+		log.Warn().Err(err).Str("interview_id", interviewID.String()).Msg("Failed to update session cache")
+	}
+}
+
 func (s *chatService) GetHistory(interviewID uuid.UUID) ([]model.Message, error) {
-	return s.msgRepo.FindMessagesByInterviewID(interviewID)
+	return s.msgRepo.FindMessagesByInterviewID(interviewID, model.ConversationRoles...)
+}
+
+func (s *chatService) GetHistorySince(interviewID uuid.UUID, lastMessageID uuid.UUID) ([]model.Message, error) {
+	return s.msgRepo.FindMessagesAfter(interviewID, lastMessageID, model.ConversationRoles...)
 }
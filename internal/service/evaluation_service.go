@@ -0,0 +1,425 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"minos/config"
+	"minos/internal/llm"
+	"minos/internal/model"
+	"minos/internal/queue"
+	"minos/internal/ratelimit"
+	"minos/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/datatypes"
+	"gorm.io/gorm"
+)
+
+// evaluationQueue is the Redis queue evaluation jobs go through. A job's ID
+// is the ID of the Evaluation row it fills in.
+const evaluationQueue = "evaluations"
+
+// consumerTTL is how long this instance's in-flight jobs stay its own without
+// a heartbeat; past it, other instances take them over.
+const consumerTTL = 30 * time.Second
+
+type EvaluationService interface {
+	// RequestEvaluation queues an evaluation of a completed interview. An
+	// evaluation that is already queued, running or done is returned as is;
+	// a failed one is reset and queued again. One that cannot be queued is
+	// left failed.
+	RequestEvaluation(interview *model.Interview) (*model.Evaluation, error)
+	GetEvaluation(interviewID uuid.UUID) (*model.Evaluation, error)
+	// Start launches the queue workers, Stop cancels them and waits.
+	Start() error
+	Stop(ctx context.Context) error
+}
+
+type evaluationService struct {
+	evalRepo       repository.EvaluationRepository
+	interviewRepo  repository.InterviewRepository
+	msgRepo        repository.MessageRepository
+	submissionRepo repository.SubmissionRepository
+	llmProvider    llm.Provider
+	prompts        PromptResolver
+	queue          queue.Queue
+	budget         ratelimit.TokenBudget
+	prices         llm.PriceTable
+	cfg            *config.Config
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewEvaluationService(
+	evalRepo repository.EvaluationRepository,
+	interviewRepo repository.InterviewRepository,
+	msgRepo repository.MessageRepository,
+	submissionRepo repository.SubmissionRepository,
+	llmProvider llm.Provider,
+	prompts PromptResolver,
+	q queue.Queue,
+	budget ratelimit.TokenBudget,
+	prices llm.PriceTable,
+	cfg *config.Config,
+) EvaluationService {
+	return &evaluationService{
+		evalRepo:       evalRepo,
+		interviewRepo:  interviewRepo,
+		msgRepo:        msgRepo,
+		submissionRepo: submissionRepo,
+		llmProvider:    llmProvider,
+		prompts:        prompts,
+		queue:          q,
+		budget:         budget,
+		prices:         prices,
+		cfg:            cfg,
+	}
+}
+
+func (s *evaluationService) RequestEvaluation(interview *model.Interview) (*model.Evaluation, error) {
+	evaluation, err := s.evalRepo.FindEvaluationByInterviewID(interview.ID)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	switch {
+	case evaluation == nil:
+		evaluation = &model.Evaluation{
+			InterviewID: interview.ID,
+			Status:      model.EvaluationStatusPending,
+		}
+		if err := s.evalRepo.CreateEvaluation(evaluation); err != nil {
+			return nil, err
+		}
+	case evaluation.Status == model.EvaluationStatusFailed:
+		evaluation.Status = model.EvaluationStatusPending
+		evaluation.Error = ""
+		evaluation.Attempts = 0
+		evaluation.NextAttemptAt = nil
+		if err := s.evalRepo.UpdateEvaluation(evaluation); err != nil {
+			return nil, err
+		}
+	default:
+		return evaluation, nil
+	}
+
+	job := &queue.Job{ID: evaluation.ID.String(), Attempt: 1}
+	if err := s.queue.Enqueue(context.Background(), evaluationQueue, job); err != nil {
+		// A pending evaluation without a job would never run nor be queued
+		// again; as failed, the next request retries it
+		evaluation.Status = model.EvaluationStatusFailed
+		evaluation.Error = fmt.Sprintf("failed to enqueue: %v", err)
+		if updateErr := s.evalRepo.UpdateEvaluation(evaluation); updateErr != nil {
+			log.Error().Err(updateErr).Str("evaluation_id", evaluation.ID.String()).Msg("Failed to mark unqueued evaluation as failed")
+		}
+		return nil, fmt.Errorf("failed to enqueue evaluation: %w", err)
+	}
+	log.Info().Str("interview_id", interview.ID.String()).Str("evaluation_id", evaluation.ID.String()).Msg("Evaluation queued")
+	return evaluation, nil
+}
+
+func (s *evaluationService) GetEvaluation(interviewID uuid.UUID) (*model.Evaluation, error) {
+	return s.evalRepo.FindEvaluationByInterviewID(interviewID)
+}
+
+func (s *evaluationService) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	// Register before taking jobs, then pick up the ones crashed instances left
+	if err := s.queue.Heartbeat(ctx, evaluationQueue, consumerTTL); err != nil {
+		log.Error().Err(err).Msg("Failed to register evaluation consumer")
+	}
+	s.reclaim(ctx)
+
+	s.wg.Add(1)
+	go s.promote(ctx)
+	s.wg.Add(1)
+	go s.heartbeat(ctx)
+	for i := 0; i < s.cfg.Evaluation.Workers; i++ {
+		s.wg.Add(1)
+		go s.work(ctx)
+	}
+	log.Info().Int("workers", s.cfg.Evaluation.Workers).Msg("Evaluation workers started")
+	return nil
+}
+
+func (s *evaluationService) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// promote moves retries whose backoff has elapsed back onto the ready queue.
+func (s *evaluationService) promote(ctx context.Context) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := s.queue.PromoteDue(ctx, evaluationQueue); err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to promote delayed evaluations")
+			}
+		}
+	}
+}
+
+// heartbeat keeps this instance's in-flight jobs its own and takes over the
+// jobs of instances that stopped beating.
+func (s *evaluationService) heartbeat(ctx context.Context) {
+	defer s.wg.Done()
+	ticker := time.NewTicker(consumerTTL / 3)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.queue.Heartbeat(ctx, evaluationQueue, consumerTTL); err != nil && ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to renew evaluation consumer heartbeat")
+			}
+			s.reclaim(ctx)
+		}
+	}
+}
+
+// reclaim requeues jobs left in flight by instances that crashed or stopped.
+func (s *evaluationService) reclaim(ctx context.Context) {
+	if n, err := s.queue.Reclaim(ctx, evaluationQueue); err != nil {
+		if ctx.Err() == nil {
+			log.Error().Err(err).Msg("Failed to reclaim in-flight evaluations")
+		}
+	} else if n > 0 {
+		log.Info().Int("count", n).Msg("Reclaimed in-flight evaluations")
+	}
+}
+
+func (s *evaluationService) work(ctx context.Context) {
+	defer s.wg.Done()
+	for ctx.Err() == nil {
+		job, err := s.queue.Dequeue(ctx, evaluationQueue, 5*time.Second)
+		if err != nil {
+			if ctx.Err() == nil {
+				log.Error().Err(err).Msg("Failed to dequeue evaluation")
+				time.Sleep(time.Second)
+			}
+			continue
+		}
+		if job == nil {
+			continue
+		}
+
+		s.process(ctx, job)
+		if ctx.Err() != nil {
+			// Shutting down mid-job: leave it in flight to be reclaimed once the heartbeat expires
+			return
+		}
+		if err := s.queue.Ack(context.Background(), evaluationQueue, job); err != nil {
+			log.Error().Err(err).Str("evaluation_id", job.ID).Msg("Failed to acknowledge evaluation job")
+		}
+	}
+}
+
+// process runs one attempt of an evaluation job and schedules a retry with
+// exponential backoff when it fails.
+func (s *evaluationService) process(ctx context.Context, job *queue.Job) {
+	logger := log.With().Str("evaluation_id", job.ID).Int("attempt", job.Attempt).Logger()
+
+	id, err := uuid.Parse(job.ID)
+	if err != nil {
+		logger.Error().Err(err).Msg("Invalid evaluation job")
+		return
+	}
+	evaluation, err := s.evalRepo.FindEvaluationByID(id)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to load evaluation for job")
+		return
+	}
+	if evaluation.Status == model.EvaluationStatusSucceeded || evaluation.Status == model.EvaluationStatusFailed {
+		return // Duplicate delivery of a finished job
+	}
+
+	evaluation.Status = model.EvaluationStatusRunning
+	evaluation.Attempts = job.Attempt
+	evaluation.NextAttemptAt = nil
+	if err := s.evalRepo.UpdateEvaluation(evaluation); err != nil {
+		logger.Error().Err(err).Msg("Failed to mark evaluation running")
+		return
+	}
+
+	err = s.evaluate(ctx, evaluation)
+	if ctx.Err() != nil {
+		return
+	}
+	if err == nil {
+		evaluation.Status = model.EvaluationStatusSucceeded
+		evaluation.Error = ""
+		if err := s.evalRepo.UpdateEvaluation(evaluation); err != nil {
+			logger.Error().Err(err).Msg("Failed to save evaluation")
+		}
+		logger.Info().Msg("Evaluation succeeded")
+		return
+	}
+
+	evaluation.Error = err.Error()
+	if job.Attempt >= s.cfg.Evaluation.MaxAttempts {
+		logger.Error().Err(err).Msg("Evaluation failed, giving up")
+		evaluation.Status = model.EvaluationStatusFailed
+		if err := s.evalRepo.UpdateEvaluation(evaluation); err != nil {
+			logger.Error().Err(err).Msg("Failed to save evaluation")
+		}
+		return
+	}
+
+	retryAt := time.Now().Add(s.backoff(job.Attempt))
+	logger.Warn().Err(err).Time("retry_at", retryAt).Msg("Evaluation failed, retrying")
+	evaluation.Status = model.EvaluationStatusPending
+	evaluation.NextAttemptAt = &retryAt
+	if err := s.evalRepo.UpdateEvaluation(evaluation); err != nil {
+		logger.Error().Err(err).Msg("Failed to save evaluation")
+	}
+	retry := &queue.Job{ID: job.ID, Attempt: job.Attempt + 1}
+	if err := s.queue.EnqueueAt(context.Background(), evaluationQueue, retry, retryAt); err != nil {
+		logger.Error().Err(err).Msg("Failed to schedule evaluation retry")
+	}
+}
+
+// backoff doubles the base delay on every attempt, up to the configured cap.
+func (s *evaluationService) backoff(attempt int) time.Duration {
+	delay := time.Duration(s.cfg.Evaluation.BackoffSeconds) * time.Second
+	limit := time.Duration(s.cfg.Evaluation.MaxBackoffSeconds) * time.Second
+	for i := 1; i < attempt && delay < limit; i++ {
+		delay *= 2
+	}
+	if delay > limit {
+		delay = limit
+	}
+	return delay
+}
+
+type evalResult struct {
+	ProblemSolvingScore int      `json:"problem_solving_score"`
+	CodeQualityScore    int      `json:"code_quality_score"`
+	CommunicationScore  int      `json:"communication_score"`
+	TechnicalScore      int      `json:"technical_score"`
+	OverallScore        int      `json:"overall_score"`
+	Strengths           []string `json:"strengths"`
+	Improvements        []string `json:"improvements"`
+	DetailedFeedback    string   `json:"detailed_feedback"`
+}
+
+// evaluate asks the evaluator for a schema-checked evaluation of the
+// interview and fills in the scores.
+func (s *evaluationService) evaluate(ctx context.Context, evaluation *model.Evaluation) error {
+	interviewID := evaluation.InterviewID
+
+	// 1. Gather Context
+	interview, err := s.interviewRepo.FindInterviewByID(interviewID)
+	if err != nil {
+		return err
+	}
+	msgs, _ := s.msgRepo.FindMessagesByInterviewID(interviewID, model.ConversationRoles...)
+	submissions, _ := s.submissionRepo.FindSubmissionsByInterviewID(interviewID)
+
+	transcript := ""
+	for _, m := range msgs {
+		if m.HintLevel != model.HintLevelNone {
+			transcript += fmt.Sprintf("[%s, %s hint]: %s\n", m.Role, m.HintLevel, m.Content)
+			continue
+		}
+		transcript += fmt.Sprintf("[%s]: %s\n", m.Role, m.Content)
+	}
+
+	subsText := ""
+	for _, sub := range submissions {
+		subsText += fmt.Sprintf("Code (%s): %s\nResult: %s\n\n", sub.Language, sub.Code, sub.AIFeedback)
+	}
+
+	// 2. Call the LLM for Evaluation
+	evaluatorPrompt, err := s.prompts.Resolve(llm.PromptNameEvaluator)
+	if err != nil {
+		return err
+	}
+	evaluation.PromptTemplateID = evaluatorPrompt.TemplateID
+	evaluation.PromptVersion = evaluatorPrompt.Version
+
+	prompt, err := evaluatorPrompt.Render(map[string]interface{}{
+		"problem":     string(interview.ProblemSnapshot),
+		"transcript":  transcript,
+		"submissions": subsText,
+	})
+	if err != nil {
+		return err
+	}
+	if interview.Status == model.InterviewStatusAbandoned {
+		evaluation.Partial = true
+		prompt += "\nNote: the candidate abandoned this interview before finishing. Evaluate only what they demonstrated and do not penalize communication for the missing ending."
+	}
+	if interview.HintsUsed > 0 {
+		prompt += fmt.Sprintf("\nNote: the candidate asked for %d hint(s), up to the %s level; they are marked in the transcript. A fixed penalty for them is applied to the scores afterwards, so judge what the candidate did with them and do not lower the scores for the hints themselves.", interview.HintsUsed, model.HintLevel(interview.HintsUsed))
+	}
+	prompt += "\nPlease output the result as a valid JSON object with keys: problem_solving_score, code_quality_score, communication_score, technical_score, overall_score (integers from 0 to 10), strengths (array), improvements (array), detailed_feedback."
+
+	var res evalResult
+	resp, err := llm.GenerateValidated(ctx, s.llmProvider, prompt, llm.EvaluationSchema, &res, s.cfg.LLM.StructuredMaxAttempts)
+	// Evaluations always run, but what they cost still counts for the user
+	chargeBudget(s.budget, interview.UserID, resp)
+	evaluation.TokenUsage.Add(tokenUsage(s.prices, resp))
+	if resp != nil {
+		evaluation.Model = resp.Model
+	}
+	if err != nil {
+		return err
+	}
+
+	// 3. Fill in the Evaluation
+	strengthsJSON, _ := json.Marshal(res.Strengths)
+	improvementsJSON, _ := json.Marshal(res.Improvements)
+
+	evaluation.ProblemSolvingScore = res.ProblemSolvingScore
+	evaluation.CodeQualityScore = res.CodeQualityScore
+	evaluation.CommunicationScore = res.CommunicationScore
+	evaluation.TechnicalScore = res.TechnicalScore
+	evaluation.OverallScore = res.OverallScore
+	applyHintPenalty(evaluation, interview.HintsUsed, s.cfg.Interview.HintPenalties)
+	evaluation.Strengths = datatypes.JSON(strengthsJSON)
+	evaluation.Improvements = datatypes.JSON(improvementsJSON)
+	evaluation.DetailedFeedback = res.DetailedFeedback
+	return nil
+}
+
+// applyHintPenalty takes the penalty of every hint level used off the
+// problem solving and overall scores.
+func applyHintPenalty(evaluation *model.Evaluation, hintsUsed int, penalties []int) {
+	evaluation.HintsUsed = hintsUsed
+	evaluation.HintPenalty = 0
+	for level := 1; level <= hintsUsed && level <= len(penalties); level++ {
+		evaluation.HintPenalty += penalties[level-1]
+	}
+	evaluation.ProblemSolvingScore = max(evaluation.ProblemSolvingScore-evaluation.HintPenalty, 0)
+	evaluation.OverallScore = max(evaluation.OverallScore-evaluation.HintPenalty, 0)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type ExperimentService interface {
+	CreateExperiment(input *dto.ExperimentCreate) (*model.Experiment, error)
+	GetExperiments(promptName string) ([]model.Experiment, error)
+	GetExperimentByID(id uint) (*model.Experiment, error)
+	StartExperiment(id uint) (*model.Experiment, error)
+	StopExperiment(id uint) (*model.Experiment, error)
+	GetExperimentReport(id uint) (*dto.ExperimentReport, error)
+	// Assign returns the arm of the running experiment on promptName for the
+	// user, or nil when no experiment is running. The same user always lands
+	// in the same arm of a given experiment.
+	Assign(promptName string, userID uuid.UUID) (*model.Experiment, *model.ExperimentArm, error)
+}
+
+type experimentService struct {
+	repo         repository.ExperimentRepository
+	templateRepo repository.PromptTemplateRepository
+}
+
+func NewExperimentService(repo repository.ExperimentRepository, templateRepo repository.PromptTemplateRepository) ExperimentService {
+	return &experimentService{
+		repo:         repo,
+		templateRepo: templateRepo,
+	}
+}
+
+func (s *experimentService) CreateExperiment(input *dto.ExperimentCreate) (*model.Experiment, error) {
+	experiment := &model.Experiment{
+		Name:       input.Name,
+		PromptName: input.PromptName,
+		Status:     model.ExperimentStatusDraft,
+	}
+
+	seen := map[string]bool{}
+	for _, arm := range input.Arms {
+		if seen[arm.Name] {
+			return nil, fmt.Errorf("duplicate arm name '%s'", arm.Name)
+		}
+		seen[arm.Name] = true
+
+		template, err := s.templateRepo.FindPromptTemplateByNameVersion(input.PromptName, arm.Version)
+		if err != nil {
+			if errors.Is(err, gorm.ErrRecordNotFound) {
+				return nil, fmt.Errorf("prompt template with name '%s' and version '%s' not found", input.PromptName, arm.Version)
+			}
+			return nil, err
+		}
+
+		experiment.Arms = append(experiment.Arms, model.ExperimentArm{
+			Name:       arm.Name,
+			TemplateID: template.ID,
+			Version:    template.Version,
+			Weight:     arm.Weight,
+		})
+	}
+
+	if err := s.repo.CreateExperiment(experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) GetExperiments(promptName string) ([]model.Experiment, error) {
+	return s.repo.FindAllExperiments(promptName)
+}
+
+func (s *experimentService) GetExperimentByID(id uint) (*model.Experiment, error) {
+	experiment, err := s.repo.FindExperimentByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("experiment with id %d not found", id)
+		}
+		return nil, err
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) StartExperiment(id uint) (*model.Experiment, error) {
+	experiment, err := s.GetExperimentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != model.ExperimentStatusDraft {
+		return nil, fmt.Errorf("experiment '%s' is %s and cannot be started", experiment.Name, experiment.Status)
+	}
+
+	// Only one experiment may split a prompt's traffic at a time
+	running, err := s.repo.FindRunningExperiment(experiment.PromptName)
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+	if running != nil {
+		return nil, fmt.Errorf("experiment '%s' is already running on prompt '%s'", running.Name, experiment.PromptName)
+	}
+
+	now := time.Now()
+	experiment.Status = model.ExperimentStatusRunning
+	experiment.StartedAt = &now
+	if err := s.repo.UpdateExperiment(experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) StopExperiment(id uint) (*model.Experiment, error) {
+	experiment, err := s.GetExperimentByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if experiment.Status != model.ExperimentStatusRunning {
+		return nil, fmt.Errorf("experiment '%s' is not running", experiment.Name)
+	}
+
+	now := time.Now()
+	experiment.Status = model.ExperimentStatusStopped
+	experiment.StoppedAt = &now
+	if err := s.repo.UpdateExperiment(experiment); err != nil {
+		return nil, err
+	}
+	return experiment, nil
+}
+
+func (s *experimentService) GetExperimentReport(id uint) (*dto.ExperimentReport, error) {
+	experiment, err := s.GetExperimentByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := s.repo.FindArmStats(id)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ExperimentReport{
+		Experiment: experiment,
+		Arms:       stats,
+	}, nil
+}
+
+func (s *experimentService) Assign(promptName string, userID uuid.UUID) (*model.Experiment, *model.ExperimentArm, error) {
+	experiment, err := s.repo.FindRunningExperiment(promptName)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, nil
+		}
+		return nil, nil, err
+	}
+
+	arm := pickArm(experiment, userID)
+	if arm == nil {
+		return nil, nil, nil
+	}
+	return experiment, arm, nil
+}
+
+// pickArm hashes the experiment and user into [0, total weight) so the
+// assignment is sticky without storing it, and independent across experiments.
+func pickArm(experiment *model.Experiment, userID uuid.UUID) *model.ExperimentArm {
+	total := 0
+	for _, arm := range experiment.Arms {
+		total += arm.Weight
+	}
+	if total <= 0 {
+		return nil
+	}
+
+	h := fnv.New32a()
+	fmt.Fprintf(h, "%d:%s", experiment.ID, userID)
+	bucket := int(h.Sum32() % uint32(total))
+
+	for i := range experiment.Arms {
+		bucket -= experiment.Arms[i].Weight
+		if bucket < 0 {
+			return &experiment.Arms[i]
+		}
+	}
+	return nil
+}
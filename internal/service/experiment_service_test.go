@@ -0,0 +1,71 @@
+package service
+
+import (
+	"math"
+	"testing"
+
+	"minos/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func TestPickArmIsSticky(t *testing.T) {
+	experiment := &model.Experiment{ID: 1, Arms: []model.ExperimentArm{
+		{ID: 1, Name: "control", Weight: 50},
+		{ID: 2, Name: "treatment", Weight: 50},
+	}}
+	userID := uuid.New()
+
+	first := pickArm(experiment, userID)
+	if first == nil {
+		t.Fatal("pickArm returned no arm")
+	}
+	for i := 0; i < 10; i++ {
+		if arm := pickArm(experiment, userID); arm.ID != first.ID {
+			t.Fatalf("pickArm = arm %d, previously arm %d", arm.ID, first.ID)
+		}
+	}
+}
+
+func TestPickArmFollowsWeights(t *testing.T) {
+	experiment := &model.Experiment{ID: 7, Arms: []model.ExperimentArm{
+		{ID: 1, Name: "control", Weight: 1},
+		{ID: 2, Name: "treatment", Weight: 3},
+	}}
+
+	const users = 4000
+	counts := map[uint]int{}
+	for i := 0; i < users; i++ {
+		counts[pickArm(experiment, uuid.New()).ID]++
+	}
+	share := float64(counts[2]) / users
+	if math.Abs(share-0.75) > 0.05 {
+		t.Errorf("treatment share = %.3f, want about 0.75", share)
+	}
+}
+
+func TestPickArmSkipsZeroWeight(t *testing.T) {
+	experiment := &model.Experiment{ID: 3, Arms: []model.ExperimentArm{
+		{ID: 1, Name: "disabled", Weight: 0},
+		{ID: 2, Name: "only", Weight: 10},
+	}}
+	for i := 0; i < 100; i++ {
+		if arm := pickArm(experiment, uuid.New()); arm == nil || arm.ID != 2 {
+			t.Fatalf("pickArm = %v, want arm 2", arm)
+		}
+	}
+}
+
+func TestPickArmWithoutWeight(t *testing.T) {
+	cases := map[string]*model.Experiment{
+		"no arms":     {ID: 1},
+		"zero weight": {ID: 1, Arms: []model.ExperimentArm{{ID: 1, Weight: 0}}},
+	}
+	for name, experiment := range cases {
+		t.Run(name, func(t *testing.T) {
+			if arm := pickArm(experiment, uuid.New()); arm != nil {
+				t.Errorf("pickArm = arm %d, want nil", arm.ID)
+			}
+		})
+	}
+}
@@ -0,0 +1,276 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"minos/config"
+	"minos/internal/cache"
+	"minos/internal/llm"
+	"minos/internal/model"
+	"minos/internal/ratelimit"
+	"minos/internal/repository"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+)
+
+// attachedCodeMarker opens the code block appended to a candidate message.
+const attachedCodeMarker = "\n\n[USER ATTACHED CODE ("
+
+// withAttachedCode appends code to a candidate message the way it is stored
+// and sent to the interviewer.
+func withAttachedCode(content, language, code string) string {
+	if language == "" {
+		language = "unknown"
+	}
+	return content + fmt.Sprintf("%s%s)]:\n```%s\n%s\n```\n(Please review this code as part of the interview context)", attachedCodeMarker, language, language, code)
+}
+
+// splitAttachedCode separates a candidate message from the code attached to it.
+func splitAttachedCode(content string) (string, *model.CodeAttachment) {
+	i := strings.Index(content, attachedCodeMarker)
+	if i < 0 {
+		return content, nil
+	}
+	rest := content[i+len(attachedCodeMarker):]
+	language, rest, ok := strings.Cut(rest, ")]:\n```")
+	if !ok {
+		return content, nil
+	}
+	_, rest, _ = strings.Cut(rest, "\n") // Fence language
+	code, _, ok := strings.Cut(rest, "\n```\n")
+	if !ok {
+		return content, nil
+	}
+	return content[:i], &model.CodeAttachment{Language: language, Code: code}
+}
+
+// ConversationContext is what a chat turn sends to the interviewer model.
+type ConversationContext struct {
+	// SystemInstruction is the interviewer prompt, which carries the problem
+	// statement, followed by the transcript summary and preserved code
+	SystemInstruction string
+	History           []llm.Message // Turns sent verbatim, oldest first
+}
+
+// HistoryManager keeps chat turns within the model's context window. The
+// most recent turns are sent verbatim; older ones are folded into a running
+// summary stored on the interview, refreshed once enough turns pile up or
+// the history outgrows its token budget. The latest code attached in folded
+// turns, per language, is kept verbatim next to the summary.
+type HistoryManager interface {
+	// Build fits the session's turns around the instruction and the message
+	// about to be sent, updating the session when it refreshes the summary.
+	Build(session *cache.Session, instruction, message string) (*ConversationContext, error)
+}
+
+type historyManager struct {
+	interviewRepo repository.InterviewRepository
+	llmProvider   llm.Provider
+	prompts       PromptResolver
+	sessions      cache.SessionCache
+	budget        ratelimit.TokenBudget
+	prices        llm.PriceTable
+	cfg           *config.Config
+}
+
+func NewHistoryManager(
+	interviewRepo repository.InterviewRepository,
+	llmProvider llm.Provider,
+	prompts PromptResolver,
+	sessions cache.SessionCache,
+	budget ratelimit.TokenBudget,
+	prices llm.PriceTable,
+	cfg *config.Config,
+) HistoryManager {
+	return &historyManager{
+		interviewRepo: interviewRepo,
+		llmProvider:   llmProvider,
+		prompts:       prompts,
+		sessions:      sessions,
+		budget:        budget,
+		prices:        prices,
+		cfg:           cfg,
+	}
+}
+
+func (m *historyManager) Build(session *cache.Session, instruction, message string) (*ConversationContext, error) {
+	turns := unsummarizedTurns(session)
+	budget := m.cfg.Context.MaxTokens - llm.EstimateTokens(message)
+
+	// Fold older turns once a batch piled up beyond the verbatim ones, or
+	// earlier when the history no longer fits. When the instruction with the
+	// summary and preserved code fills the budget by itself, folding more
+	// would only grow it, so the history is cut instead.
+	room := budget - llm.EstimateTokens(withSummary(instruction, session))
+	fits := fittingTurns(turns, room)
+	keep := min(m.cfg.Context.KeepTurns, fits)
+	if room <= 0 {
+		log.Warn().Str("interview_id", session.InterviewID.String()).Int("max_tokens", m.cfg.Context.MaxTokens).Msg("Interviewer instruction exceeds the context budget")
+	} else if fold := len(turns) - keep; fold >= m.cfg.Context.SummarizeBatch || fits < len(turns) {
+		if err := m.summarize(session, turns[:fold]); err != nil {
+			// Without a fresh summary, drop what does not fit rather than fail the turn
+			log.Warn().Err(err).Str("interview_id", session.InterviewID.String()).Msg("Failed to summarize transcript")
+			turns = turns[len(turns)-fits:]
+		} else {
+			turns = turns[fold:]
+		}
+	}
+
+	instruction = withSummary(instruction, session)
+	turns = turns[len(turns)-fittingTurns(turns, budget-llm.EstimateTokens(instruction)):]
+
+	history := make([]llm.Message, 0, len(turns)+1)
+	if len(turns) > 0 && turns[0].Role == model.MessageRoleAssistant {
+		// Models expect the conversation to open with a user turn
+		opening := greetingKickoff
+		if session.TranscriptSummary != "" {
+			opening = "Let's continue the interview."
+		}
+		history = append(history, llm.Message{Role: llm.RoleUser, Content: opening})
+	}
+	for _, turn := range turns {
+		role := llm.RoleUser
+		if turn.Role == model.MessageRoleAssistant {
+			role = llm.RoleModel
+		}
+		history = append(history, llm.Message{Role: role, Content: turn.Content})
+	}
+	return &ConversationContext{SystemInstruction: instruction, History: history}, nil
+}
+
+// summarize folds turns into the session's summary and persists it.
+func (m *historyManager) summarize(session *cache.Session, turns []cache.Turn) error {
+	if len(turns) == 0 {
+		return nil
+	}
+
+	var transcript strings.Builder
+	code := session.PreservedCode
+	for _, turn := range turns {
+		content := turn.Content
+		if turn.Role == model.MessageRoleUser {
+			var attachment *model.CodeAttachment
+			content, attachment = splitAttachedCode(content)
+			if attachment != nil {
+				code = preserveCode(code, *attachment)
+				content += fmt.Sprintf("\n[attached %s code]", attachment.Language)
+			}
+		}
+		fmt.Fprintf(&transcript, "%s: %s\n\n", turn.Role, content)
+	}
+
+	summarizerPrompt, err := m.prompts.Resolve(llm.PromptNameSummarizer)
+	if err != nil {
+		return err
+	}
+	previous := session.TranscriptSummary
+	if previous == "" {
+		previous = "(none yet)"
+	}
+	prompt, err := summarizerPrompt.Render(map[string]interface{}{
+		"problem":    string(session.ProblemSnapshot),
+		"summary":    previous,
+		"transcript": transcript.String(),
+	})
+	if err != nil {
+		return err
+	}
+	resp, err := m.llmProvider.Generate(context.Background(), prompt)
+	chargeBudget(m.budget, session.UserID, resp)
+	if err != nil {
+		return err
+	}
+
+	through := turns[len(turns)-1].ID
+	interview := &model.Interview{
+		ID:                session.InterviewID,
+		TranscriptSummary: strings.TrimSpace(resp.Text),
+		SummarizedThrough: &through,
+		PreservedCode:     code,
+		SummaryModel:      resp.Model,
+	}
+	saved, err := m.interviewRepo.SaveTranscriptSummary(interview, session.SummarizedThrough, tokenUsage(m.prices, resp))
+	if err != nil {
+		return err
+	}
+	if !saved {
+		// A concurrent turn refreshed it first; use ours for this turn only
+		log.Info().Str("interview_id", session.InterviewID.String()).Msg("Transcript summary changed concurrently")
+	}
+
+	session.TranscriptSummary = interview.TranscriptSummary
+	session.SummarizedThrough = interview.SummarizedThrough
+	session.PreservedCode = code
+	if saved {
+		if err := m.sessions.SetMeta(context.Background(), session); err != nil {
+			log.Warn().Err(err).Str("interview_id", session.InterviewID.String()).Msg("Failed to cache transcript summary")
+		}
+	}
+	return nil
+}
+
+// unsummarizedTurns returns the turns after the last one folded into the summary.
+func unsummarizedTurns(session *cache.Session) []cache.Turn {
+	if session.SummarizedThrough == nil {
+		return session.Turns
+	}
+	for i, turn := range session.Turns {
+		if turn.ID == *session.SummarizedThrough {
+			return session.Turns[i+1:]
+		}
+	}
+	// Folded turns already fell out of the cached window
+	return session.Turns
+}
+
+// fittingTurns counts how many of the most recent turns fit in budget tokens.
+func fittingTurns(turns []cache.Turn, budget int) int {
+	n := 0
+	for i := len(turns) - 1; i >= 0; i-- {
+		budget -= llm.EstimateTokens(turns[i].Content)
+		if budget < 0 {
+			break
+		}
+		n++
+	}
+	return n
+}
+
+// maxPreservedCode caps the languages whose code is kept next to the summary.
+const maxPreservedCode = 3
+
+// preserveCode keeps attachment as the latest code in its language, dropping
+// the languages attached longest ago beyond maxPreservedCode.
+func preserveCode(code []model.CodeAttachment, attachment model.CodeAttachment) []model.CodeAttachment {
+	kept := make([]model.CodeAttachment, 0, len(code)+1)
+	for _, c := range code {
+		if c.Language != attachment.Language {
+			kept = append(kept, c)
+		}
+	}
+	kept = append(kept, attachment)
+	if len(kept) > maxPreservedCode {
+		kept = kept[len(kept)-maxPreservedCode:]
+	}
+	return kept
+}
+
+// withSummary appends the transcript summary and preserved code to the
+// interviewer instruction.
+func withSummary(instruction string, session *cache.Session) string {
+	if session.TranscriptSummary == "" && len(session.PreservedCode) == 0 {
+		return instruction
+	}
+
+	var b strings.Builder
+	b.WriteString(instruction)
+	if session.TranscriptSummary != "" {
+		b.WriteString("\n\nSummary of the earlier part of the interview:\n")
+		b.WriteString(session.TranscriptSummary)
+	}
+	for _, attachment := range session.PreservedCode {
+		fmt.Fprintf(&b, "\n\nLatest %s code the candidate attached earlier:\n```%s\n%s\n```", attachment.Language, attachment.Language, attachment.Code)
+	}
+	return b.String()
+}
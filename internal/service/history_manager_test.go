@@ -0,0 +1,204 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"minos/config"
+	"minos/internal/cache"
+	"minos/internal/llm"
+	"minos/internal/model"
+
+	"github.com/google/uuid"
+)
+
+func turn(role model.MessageRole, content string) cache.Turn {
+	return cache.Turn{ID: uuid.New(), Role: role, Content: content}
+}
+
+func TestAttachedCodeRoundTrip(t *testing.T) {
+	content := withAttachedCode("Here is my solution.", "go", "func main() {\n}")
+
+	message, attachment := splitAttachedCode(content)
+	if message != "Here is my solution." {
+		t.Errorf("message = %q, want the text before the code", message)
+	}
+	want := &model.CodeAttachment{Language: "go", Code: "func main() {\n}"}
+	if !reflect.DeepEqual(attachment, want) {
+		t.Errorf("attachment = %#v, want %#v", attachment, want)
+	}
+
+	if message, attachment := splitAttachedCode("No code here."); message != "No code here." || attachment != nil {
+		t.Errorf("splitAttachedCode without code = %q, %#v", message, attachment)
+	}
+	if _, attachment := splitAttachedCode(withAttachedCode("", "", "x")); attachment == nil || attachment.Language != "unknown" {
+		t.Errorf("attachment without a language = %#v, want language unknown", attachment)
+	}
+}
+
+func TestFittingTurns(t *testing.T) {
+	// Four characters make one estimated token
+	turns := []cache.Turn{
+		turn(model.MessageRoleUser, strings.Repeat("a", 40)),
+		turn(model.MessageRoleAssistant, strings.Repeat("b", 20)),
+		turn(model.MessageRoleUser, strings.Repeat("c", 8)),
+	}
+	tests := []struct {
+		budget int
+		want   int
+	}{
+		{0, 0},
+		{1, 0},
+		{2, 1},
+		{7, 2},
+		{16, 2},
+		{17, 3},
+		{100, 3},
+	}
+	for _, tt := range tests {
+		if got := fittingTurns(turns, tt.budget); got != tt.want {
+			t.Errorf("fittingTurns(budget %d) = %d, want %d", tt.budget, got, tt.want)
+		}
+	}
+}
+
+func TestUnsummarizedTurns(t *testing.T) {
+	turns := []cache.Turn{
+		turn(model.MessageRoleAssistant, "Hello"),
+		turn(model.MessageRoleUser, "Hi"),
+		turn(model.MessageRoleAssistant, "Go on"),
+	}
+	session := &cache.Session{Turns: turns}
+	if got := unsummarizedTurns(session); len(got) != 3 {
+		t.Errorf("unsummarizedTurns without a summary = %d turns, want 3", len(got))
+	}
+
+	session.SummarizedThrough = &turns[1].ID
+	if got := unsummarizedTurns(session); len(got) != 1 || got[0].ID != turns[2].ID {
+		t.Errorf("unsummarizedTurns = %v, want the last turn", got)
+	}
+
+	gone := uuid.New()
+	session.SummarizedThrough = &gone
+	if got := unsummarizedTurns(session); len(got) != 3 {
+		t.Errorf("unsummarizedTurns past the cached window = %d turns, want 3", len(got))
+	}
+}
+
+func TestPreserveCode(t *testing.T) {
+	goV1 := model.CodeAttachment{Language: "go", Code: "v1"}
+	goV2 := model.CodeAttachment{Language: "go", Code: "v2"}
+	python := model.CodeAttachment{Language: "python", Code: "p1"}
+
+	code := preserveCode(nil, goV1)
+	code = preserveCode(code, python)
+	code = preserveCode(code, goV2)
+	if want := []model.CodeAttachment{python, goV2}; !reflect.DeepEqual(code, want) {
+		t.Errorf("preserveCode = %v, want %v", code, want)
+	}
+
+	for _, language := range []string{"cpp", "javascript", "rust"} {
+		code = preserveCode(code, model.CodeAttachment{Language: language, Code: "x"})
+	}
+	if len(code) != maxPreservedCode || code[0].Language != "cpp" {
+		t.Errorf("preserveCode = %v, want the last %d languages", code, maxPreservedCode)
+	}
+}
+
+func TestWithSummary(t *testing.T) {
+	session := &cache.Session{}
+	if got := withSummary("Interview.", session); got != "Interview." {
+		t.Errorf("withSummary without a summary = %q", got)
+	}
+
+	session.TranscriptSummary = "The candidate proposed a hash map."
+	session.PreservedCode = []model.CodeAttachment{{Language: "go", Code: "v2"}, {Language: "python", Code: "p1"}}
+	got := withSummary("Interview.", session)
+	for _, want := range []string{"Interview.", session.TranscriptSummary, "Latest go code", "v2", "Latest python code", "p1"} {
+		if !strings.Contains(got, want) {
+			t.Errorf("withSummary = %q, missing %q", got, want)
+		}
+	}
+}
+
+// failingPrompts fails every lookup so summaries can never be refreshed.
+type failingPrompts struct{}
+
+func (failingPrompts) Resolve(name string) (*ResolvedPrompt, error) {
+	return nil, errors.New("no prompt")
+}
+
+func (failingPrompts) ResolvePinned(name string, templateID *uint) (*ResolvedPrompt, error) {
+	return nil, errors.New("no prompt")
+}
+
+func newTestHistoryManager(maxTokens, keepTurns, batch int) *historyManager {
+	return &historyManager{
+		prompts: failingPrompts{},
+		cfg: &config.Config{Context: config.ContextConfig{
+			MaxTokens:      maxTokens,
+			KeepTurns:      keepTurns,
+			SummarizeBatch: batch,
+		}},
+	}
+}
+
+func TestBuildSendsHistoryThatFits(t *testing.T) {
+	session := &cache.Session{InterviewID: uuid.New(), Turns: []cache.Turn{
+		turn(model.MessageRoleAssistant, "Hello, how would you start?"),
+		turn(model.MessageRoleUser, "With a hash map."),
+	}}
+
+	conversation, err := newTestHistoryManager(1000, 10, 10).Build(session, "Interview.", "Next message")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if conversation.SystemInstruction != "Interview." {
+		t.Errorf("SystemInstruction = %q", conversation.SystemInstruction)
+	}
+	want := []llm.Message{
+		{Role: llm.RoleUser, Content: greetingKickoff},
+		{Role: llm.RoleModel, Content: "Hello, how would you start?"},
+		{Role: llm.RoleUser, Content: "With a hash map."},
+	}
+	if !reflect.DeepEqual(conversation.History, want) {
+		t.Errorf("History = %#v, want %#v", conversation.History, want)
+	}
+}
+
+func TestBuildDropsWhatDoesNotFitWithoutSummary(t *testing.T) {
+	session := &cache.Session{InterviewID: uuid.New(), Turns: []cache.Turn{
+		turn(model.MessageRoleUser, strings.Repeat("a", 400)),
+		turn(model.MessageRoleAssistant, strings.Repeat("b", 400)),
+		turn(model.MessageRoleUser, "short"),
+	}}
+
+	// Room for the instruction, the message and only the latest turn
+	conversation, err := newTestHistoryManager(20, 10, 10).Build(session, "Interview.", "Next")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if want := []llm.Message{{Role: llm.RoleUser, Content: "short"}}; !reflect.DeepEqual(conversation.History, want) {
+		t.Errorf("History = %#v, want %#v", conversation.History, want)
+	}
+}
+
+func TestBuildDoesNotFoldWhenTheInstructionFillsTheBudget(t *testing.T) {
+	session := &cache.Session{InterviewID: uuid.New(), Turns: []cache.Turn{
+		turn(model.MessageRoleAssistant, "Hello"),
+		turn(model.MessageRoleUser, "Hi"),
+	}}
+	// Without a resolver any summarize call would panic
+	m := newTestHistoryManager(10, 10, 1)
+	m.prompts = nil
+
+	conversation, err := m.Build(session, strings.Repeat("i", 100), "Next")
+	if err != nil {
+		t.Fatalf("Build: %v", err)
+	}
+	if len(conversation.History) != 0 {
+		t.Errorf("History = %#v, want none", conversation.History)
+	}
+}
@@ -0,0 +1,39 @@
+package service
+
+import (
+	"minos/internal/model"
+	"time"
+)
+
+// activeTime is how long an interview has been running, pauses excluded.
+func activeTime(activeSeconds int64, resumedAt *time.Time, now time.Time) time.Duration {
+	active := time.Duration(activeSeconds) * time.Second
+	if resumedAt != nil && now.After(*resumedAt) {
+		active += now.Sub(*resumedAt)
+	}
+	return active
+}
+
+// remainingTime is the time left in a timed interview, never negative. ok is
+// false for untimed interviews.
+func remainingTime(durationMinutes int, activeSeconds int64, resumedAt *time.Time, now time.Time) (remaining time.Duration, ok bool) {
+	if durationMinutes <= 0 {
+		return 0, false
+	}
+	remaining = time.Duration(durationMinutes)*time.Minute - activeTime(activeSeconds, resumedAt, now)
+	if remaining < 0 {
+		remaining = 0
+	}
+	return remaining, true
+}
+
+// stopClock folds the running stretch into ActiveSeconds, capped at the
+// interview's duration, and stops the clock.
+func stopClock(interview *model.Interview, now time.Time) {
+	active := activeTime(interview.ActiveSeconds, interview.ResumedAt, now)
+	if budget := time.Duration(interview.DurationMinutes) * time.Minute; budget > 0 && active > budget {
+		active = budget
+	}
+	interview.ActiveSeconds = int64(active / time.Second)
+	interview.ResumedAt = nil
+}
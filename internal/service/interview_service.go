@@ -2,79 +2,182 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"minos/config"
+	"minos/internal/cache"
 	"minos/internal/dto"
 	"minos/internal/llm"
-	"minos/internal/llm/gemini"
 	"minos/internal/model"
+	"minos/internal/ratelimit"
 	"minos/internal/repository"
+	"minos/internal/runner"
 	"strings"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
 	"gorm.io/datatypes"
+	"gorm.io/gorm"
 )
 
+// ErrTooManyActiveInterviews is returned by StartInterview when the user
+// already has the maximum number of active interviews.
+var ErrTooManyActiveInterviews = errors.New("too many active interviews")
+
+// ErrInvalidQuery is returned when list parameters cannot be parsed.
+var ErrInvalidQuery = errors.New("invalid query")
+
+// ErrInvalidInterviewState is returned when an interview is not in a status
+// that allows the requested transition.
+var ErrInvalidInterviewState = errors.New("invalid interview state")
+
 type InterviewService interface {
 	StartInterview(req *dto.StartInterviewRequest) (*dto.StartInterviewResponse, error)
-	GetInterview(id uuid.UUID) (*model.Interview, error)
+	// GetInterview returns the interview with the usage of its LLM calls.
+	GetInterview(id uuid.UUID) (*dto.InterviewDetailResponse, error)
+	GetInterviewOwner(id uuid.UUID) (uuid.UUID, error)
+	ListInterviews(query *dto.InterviewListQuery) (*dto.InterviewListResponse, error)
 	EndInterview(id uuid.UUID) (*dto.EndInterviewResponse, error)
+	// PauseInterview stops the clock of an active interview.
+	PauseInterview(id uuid.UUID) (*dto.InterviewTimeResponse, error)
+	// ResumeInterview restarts the clock of a paused interview.
+	ResumeInterview(id uuid.UUID) (*dto.InterviewTimeResponse, error)
 }
 
 type interviewService struct {
-	repo           repository.InterviewRepository
-	evalRepo       repository.EvaluationRepository
-	msgRepo        repository.MessageRepository
-	submissionRepo repository.SubmissionRepository
-	geminiClient   *gemini.Client
+	repo        repository.InterviewRepository
+	msgRepo     repository.MessageRepository
+	problemRepo repository.ProblemRepository
+	prompts     PromptResolver
+	experiments ExperimentService
+	evaluations EvaluationService
+	chat        ChatService
+	sessions    cache.SessionCache
+	usageRepo   repository.UsageRepository
+	budget      ratelimit.TokenBudget
+	cfg         *config.Config
 }
 
 func NewInterviewService(
 	repo repository.InterviewRepository,
-	evalRepo repository.EvaluationRepository,
 	msgRepo repository.MessageRepository,
-	submissionRepo repository.SubmissionRepository,
-	geminiClient *gemini.Client,
+	problemRepo repository.ProblemRepository,
+	usageRepo repository.UsageRepository,
+	prompts PromptResolver,
+	experiments ExperimentService,
+	evaluations EvaluationService,
+	chat ChatService,
+	sessions cache.SessionCache,
+	budget ratelimit.TokenBudget,
+	cfg *config.Config,
 ) InterviewService {
 	return &interviewService{
-		repo:           repo,
-		evalRepo:       evalRepo,
-		msgRepo:        msgRepo,
-		submissionRepo: submissionRepo,
-		geminiClient:   geminiClient,
+		repo:        repo,
+		msgRepo:     msgRepo,
+		problemRepo: problemRepo,
+		usageRepo:   usageRepo,
+		prompts:     prompts,
+		experiments: experiments,
+		evaluations: evaluations,
+		chat:        chat,
+		sessions:    sessions,
+		budget:      budget,
+		cfg:         cfg,
 	}
 }
 
 func (s *interviewService) StartInterview(req *dto.StartInterviewRequest) (*dto.StartInterviewResponse, error) {
-	// 1. Create Interview Record
+	// 1. Check the budget; the per-user limit on concurrent interviews is
+	// enforced when the interview is created
+	if err := checkBudget(s.budget, req.UserID); err != nil {
+		return nil, err
+	}
+	snapshot, hidden, err := s.problemSnapshot(req)
+	if err != nil {
+		return nil, err
+	}
+
+	// 2. Create Interview Record, pinned to the current interviewer prompt or
+	// to the experiment arm the user is assigned to
+	now := time.Now()
 	interview := &model.Interview{
 		UserID:          req.UserID,
 		ProblemID:       req.ProblemID,
-		ProblemSnapshot: req.ProblemSnapshot,
+		ProblemSnapshot: snapshot,
+		HiddenTestCases: hidden,
 		Status:          model.InterviewStatusActive,
+		DurationMinutes: s.cfg.Interview.DurationMinutes,
+		ResumedAt:       &now,
+	}
+	if req.DurationMinutes != nil {
+		interview.DurationMinutes = *req.DurationMinutes
+	}
+	experiment, arm, err := s.experiments.Assign(llm.PromptNameInterviewer, req.UserID)
+	if err != nil {
+		return nil, err
 	}
-	if err := s.repo.CreateInterview(interview); err != nil {
+	var interviewerPrompt *ResolvedPrompt
+	if arm != nil {
+		interviewerPrompt, err = s.prompts.ResolvePinned(llm.PromptNameInterviewer, &arm.TemplateID)
+		interview.ExperimentID = &experiment.ID
+		interview.ExperimentArmID = &arm.ID
+	} else {
+		interviewerPrompt, err = s.prompts.Resolve(llm.PromptNameInterviewer)
+	}
+	if err != nil {
 		return nil, err
 	}
+	interview.PromptTemplateID = interviewerPrompt.TemplateID
+	interview.PromptVersion = interviewerPrompt.Version
 
-	// 2. Generate Greeting using Gemini
-	prompt := fmt.Sprintf(llm.SystemPromptInterviewer, string(req.ProblemSnapshot)) + "\n\nPlease start the interview by greeting the candidate and asking them to explain their initial thought process."
-	resp, err := s.geminiClient.GenerateContent(context.Background(), prompt)
-	greeting := "Hello! I'm ready to help you with this problem. How would you like to start?" // Default fallback
-	if err == nil && len(resp.Candidates) > 0 && len(resp.Candidates[0].Content.Parts) > 0 {
-		// Extract text
-		// Note: Simplification here
-		greeting = fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
+	// 3. Record the interviewer instruction the interview runs with, created
+	// along with the interview so a failure leaves neither behind
+	systemPrompt, err := interviewerPrompt.Render(map[string]interface{}{
+		"problem": string(snapshot),
+	})
+	if err != nil {
+		return nil, err
+	}
+	interview.Messages = []model.Message{{Role: model.MessageRoleSystem, Content: systemPrompt}}
+	if limit := s.cfg.Interview.MaxActivePerUser; limit > 0 {
+		created, err := s.repo.CreateInterviewWithinLimit(interview, limit, model.InterviewStatusActive, model.InterviewStatusPaused)
+		if err != nil {
+			return nil, err
+		}
+		if !created {
+			return nil, fmt.Errorf("%w: limit is %d", ErrTooManyActiveInterviews, limit)
+		}
+	} else if err := s.repo.CreateInterview(interview); err != nil {
+		return nil, err
 	}
 
-	// 3. Save Greeting as first message
-	msg := &model.Message{
-		InterviewID: interview.ID,
-		Role:        model.MessageRoleAssistant,
-		Content:     greeting,
+	// 4. Warm the session cache so the greeting and first turn skip Postgres
+	session := &cache.Session{
+		InterviewID:      interview.ID,
+		UserID:           interview.UserID,
+		Status:           interview.Status,
+		PromptTemplateID: interview.PromptTemplateID,
+		SystemPrompt:     systemPrompt,
+		ProblemSnapshot:  json.RawMessage(interview.ProblemSnapshot),
+		DurationMinutes:  interview.DurationMinutes,
+		ActiveSeconds:    interview.ActiveSeconds,
+		ResumedAt:        interview.ResumedAt,
+	}
+	if err := s.sessions.Set(context.Background(), session); err != nil {
+		log.Warn().Err(err).Str("interview_id", interview.ID.String()).Msg("Failed to cache session")
+	}
+
+	// 5. Greet the candidate through the chat path. The interview exists by
+	// now, so a failure here must not fail the start.
+	greeting := defaultGreeting
+	if msg, err := s.chat.Greet(interview.ID); err != nil {
+		log.Warn().Err(err).Str("interview_id", interview.ID.String()).Msg("Failed to greet, using the default greeting")
+	} else {
+		greeting = msg.Content
 	}
-	s.msgRepo.CreateMessage(msg)
 
 	return &dto.StartInterviewResponse{
 		InterviewID: interview.ID,
@@ -82,8 +185,134 @@ func (s *interviewService) StartInterview(req *dto.StartInterviewRequest) (*dto.
 	}, nil
 }
 
-func (s *interviewService) GetInterview(id uuid.UUID) (*model.Interview, error) {
-	return s.repo.FindInterviewByID(id)
+// problemSnapshot returns the snapshot sent with the request, or captures
+// one from the problem bank when there is none, along with the hidden test
+// cases kept out of it.
+func (s *interviewService) problemSnapshot(req *dto.StartInterviewRequest) (datatypes.JSON, []model.ProblemTestCase, error) {
+	if len(req.ProblemSnapshot) > 0 && string(req.ProblemSnapshot) != "null" {
+		snapshot, tests := runner.SplitHiddenTestCases(req.ProblemSnapshot)
+		hidden := make([]model.ProblemTestCase, 0, len(tests))
+		for _, tc := range tests {
+			hidden = append(hidden, model.ProblemTestCase{Input: tc.Input, Expected: tc.Expected})
+		}
+		return snapshot, hidden, nil
+	}
+	problem, err := s.problemRepo.FindProblemByID(req.ProblemID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("%w: %s", ErrProblemNotFound, req.ProblemID)
+		}
+		return nil, nil, err
+	}
+	snapshot, err := problem.Snapshot()
+	return snapshot, problem.HiddenTestCases, err
+}
+
+func (s *interviewService) GetInterview(id uuid.UUID) (*dto.InterviewDetailResponse, error) {
+	interview, err := s.repo.FindInterviewByID(id)
+	if err != nil {
+		return nil, err
+	}
+	usage, err := s.usageRepo.SumInterviewUsage(id)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.InterviewDetailResponse{Interview: interview, Usage: usage}, nil
+}
+
+func (s *interviewService) GetInterviewOwner(id uuid.UUID) (uuid.UUID, error) {
+	return s.repo.FindInterviewOwner(id)
+}
+
+func (s *interviewService) ListInterviews(query *dto.InterviewListQuery) (*dto.InterviewListResponse, error) {
+	filter, err := toInterviewFilter(query)
+	if err != nil {
+		return nil, err
+	}
+
+	// Fetch one extra row to know whether there is a next page
+	limit := filter.Limit
+	filter.Limit++
+	summaries, err := s.repo.SearchInterviews(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &dto.InterviewListResponse{Items: summaries}
+	if len(summaries) > limit {
+		res.Items = summaries[:limit]
+		last := res.Items[limit-1]
+		cursor := repository.InterviewCursor{ID: last.ID, StartedAt: last.StartedAt, OverallScore: -1}
+		if last.OverallScore != nil {
+			cursor.OverallScore = *last.OverallScore
+		}
+		raw, _ := json.Marshal(cursor)
+		res.NextCursor = base64.RawURLEncoding.EncodeToString(raw)
+	}
+	if res.Items == nil {
+		res.Items = []model.InterviewSummary{}
+	}
+	return res, nil
+}
+
+func toInterviewFilter(query *dto.InterviewListQuery) (repository.InterviewFilter, error) {
+	filter := repository.InterviewFilter{
+		SortBy: repository.InterviewSortStartedAt,
+		Desc:   query.Order != "asc",
+		Limit:  query.Limit,
+	}
+	if query.Sort != "" {
+		filter.SortBy = query.Sort
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = 20
+	}
+
+	if query.UserID != "" {
+		id, err := uuid.Parse(query.UserID)
+		if err != nil {
+			return filter, fmt.Errorf("%w: user_id must be a UUID", ErrInvalidQuery)
+		}
+		filter.UserID = &id
+	}
+	if query.ProblemID != "" {
+		id, err := uuid.Parse(query.ProblemID)
+		if err != nil {
+			return filter, fmt.Errorf("%w: problem_id must be a UUID", ErrInvalidQuery)
+		}
+		filter.ProblemID = &id
+	}
+	for _, status := range strings.Split(query.Status, ",") {
+		if status = strings.TrimSpace(status); status != "" {
+			filter.Statuses = append(filter.Statuses, model.InterviewStatus(status))
+		}
+	}
+	if query.From != "" {
+		from, err := time.Parse(time.RFC3339, query.From)
+		if err != nil {
+			return filter, fmt.Errorf("%w: from must be an RFC 3339 timestamp", ErrInvalidQuery)
+		}
+		filter.From = &from
+	}
+	if query.To != "" {
+		to, err := time.Parse(time.RFC3339, query.To)
+		if err != nil {
+			return filter, fmt.Errorf("%w: to must be an RFC 3339 timestamp", ErrInvalidQuery)
+		}
+		filter.To = &to
+	}
+	if query.Cursor != "" {
+		raw, err := base64.RawURLEncoding.DecodeString(query.Cursor)
+		var cursor repository.InterviewCursor
+		if err == nil {
+			err = json.Unmarshal(raw, &cursor)
+		}
+		if err != nil {
+			return filter, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+		}
+		filter.After = &cursor
+	}
+	return filter, nil
 }
 
 func (s *interviewService) EndInterview(id uuid.UUID) (*dto.EndInterviewResponse, error) {
@@ -92,93 +321,123 @@ func (s *interviewService) EndInterview(id uuid.UUID) (*dto.EndInterviewResponse
 		return nil, err
 	}
 
-	if interview.Status == model.InterviewStatusCompleted {
-		// Already completed, return existing evaluation
-		eval, err := s.evalRepo.FindEvaluationByInterviewID(id)
+	// 1. Update Status. Ending a completed or abandoned interview again only
+	// retries a failed evaluation.
+	if interview.Status == model.InterviewStatusActive || interview.Status == model.InterviewStatusPaused {
+		from := interview.Status
+		now := time.Now()
+		stopClock(interview, now)
+		interview.Status = model.InterviewStatusCompleted
+		interview.EndedAt = &now
+		ok, err := s.repo.TransitionInterview(interview, from)
 		if err != nil {
 			return nil, err
 		}
-		return &dto.EndInterviewResponse{
-			EvaluationID: eval.ID,
-			OverallScore: eval.OverallScore,
-			Feedback:     eval.DetailedFeedback,
-		}, nil
+		if !ok {
+			return nil, fmt.Errorf("%w: interview changed status concurrently", ErrInvalidInterviewState)
+		}
 	}
+	s.invalidateSession(id)
 
-	// 1. Update Status
-	now := time.Now()
-	interview.Status = model.InterviewStatusCompleted
-	interview.EndedAt = &now
-	s.repo.UpdateInterview(interview)
+	// 2. Queue the evaluation, its progress is polled separately
+	evaluation, err := s.evaluations.RequestEvaluation(interview)
+	if err != nil {
+		return nil, err
+	}
 
-	// 2. Gather Context
-	msgs, _ := s.msgRepo.FindMessagesByInterviewID(id)
-	submissions, _ := s.submissionRepo.FindSubmissionsByInterviewID(id)
+	return &dto.EndInterviewResponse{
+		JobID:        evaluation.ID,
+		Status:       string(evaluation.Status),
+		OverallScore: evaluation.OverallScore,
+		Feedback:     evaluation.DetailedFeedback,
+		Error:        evaluation.Error,
+	}, nil
+}
 
-	transcript := ""
-	for _, m := range msgs {
-		transcript += fmt.Sprintf("[%s]: %s\n", m.Role, m.Content)
+func (s *interviewService) PauseInterview(id uuid.UUID) (*dto.InterviewTimeResponse, error) {
+	interview, err := s.repo.FindInterviewByID(id)
+	if err != nil {
+		return nil, err
 	}
-
-	subsText := ""
-	for _, sub := range submissions {
-		subsText += fmt.Sprintf("Code (%s): %s\nResult: %s\n\n", sub.Language, sub.Code, sub.AIFeedback)
+	if interview.Status != model.InterviewStatusActive {
+		return nil, fmt.Errorf("%w: interview is %s", ErrInvalidInterviewState, interview.Status)
 	}
 
-	// 3. Call Gemini for Evaluation
-	prompt := fmt.Sprintf(llm.SystemPromptEvaluator, string(interview.ProblemSnapshot), transcript, subsText)
-	// Force JSON structure?
-	prompt += "\nPlease output the result as a valid JSON object with keys: problem_solving_score, code_quality_score, communication_score, technical_score, overall_score, strengths (array), improvements (array), detailed_feedback."
+	now := time.Now()
+	if remaining, timed := remainingTime(interview.DurationMinutes, interview.ActiveSeconds, interview.ResumedAt, now); timed && remaining <= 0 {
+		// Out of time already: finish instead of pausing
+		if _, err := s.EndInterview(id); err != nil {
+			return nil, err
+		}
+		return s.timeResponse(id)
+	}
 
-	resp, err := s.geminiClient.GenerateContent(context.Background(), prompt)
+	stopClock(interview, now)
+	interview.Status = model.InterviewStatusPaused
+	interview.PausedAt = &now
+	ok, err := s.repo.TransitionInterview(interview, model.InterviewStatusActive)
 	if err != nil {
 		return nil, err
 	}
+	if !ok {
+		return nil, fmt.Errorf("%w: interview changed status concurrently", ErrInvalidInterviewState)
+	}
+	s.invalidateSession(id)
 
-	// 4. Parse JSON Response
-	// This is tricky without strict mode. We assume Gemini follows instructions.
-	// For MVP, we might need to clean the response string (remove markdown code blocks).
-	responseText := fmt.Sprintf("%v", resp.Candidates[0].Content.Parts[0])
-	responseText = strings.TrimPrefix(responseText, "```json")
-	responseText = strings.TrimPrefix(responseText, "```")
-	responseText = strings.TrimSuffix(responseText, "```")
+	return toTimeResponse(interview, now), nil
+}
 
-	type EvalResult struct {
-		ProblemSolvingScore int      `json:"problem_solving_score"`
-		CodeQualityScore    int      `json:"code_quality_score"`
-		CommunicationScore  int      `json:"communication_score"`
-		TechnicalScore      int      `json:"technical_score"`
-		OverallScore        int      `json:"overall_score"`
-		Strengths           []string `json:"strengths"`
-		Improvements        []string `json:"improvements"`
-		DetailedFeedback    string   `json:"detailed_feedback"`
+func (s *interviewService) ResumeInterview(id uuid.UUID) (*dto.InterviewTimeResponse, error) {
+	interview, err := s.repo.FindInterviewByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status != model.InterviewStatusPaused {
+		return nil, fmt.Errorf("%w: interview is %s", ErrInvalidInterviewState, interview.Status)
 	}
 
-	var res EvalResult
-	json.Unmarshal([]byte(responseText), &res)
+	now := time.Now()
+	interview.Status = model.InterviewStatusActive
+	interview.ResumedAt = &now
+	interview.PausedAt = nil
+	ok, err := s.repo.TransitionInterview(interview, model.InterviewStatusPaused)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: interview changed status concurrently", ErrInvalidInterviewState)
+	}
+	s.invalidateSession(id)
 
-	// 5. Save Evaluation
-	strengthsJSON, _ := json.Marshal(res.Strengths)
-	improvementsJSON, _ := json.Marshal(res.Improvements)
+	return toTimeResponse(interview, now), nil
+}
 
-	evaluation := &model.Evaluation{
-		InterviewID:         id,
-		ProblemSolvingScore: res.ProblemSolvingScore,
-		CodeQualityScore:    res.CodeQualityScore,
-		CommunicationScore:  res.CommunicationScore,
-		TechnicalScore:      res.TechnicalScore,
-		OverallScore:        res.OverallScore,
-		Strengths:           datatypes.JSON(strengthsJSON),
-		Improvements:        datatypes.JSON(improvementsJSON),
-		DetailedFeedback:    res.DetailedFeedback,
+func (s *interviewService) timeResponse(id uuid.UUID) (*dto.InterviewTimeResponse, error) {
+	interview, err := s.repo.FindInterviewByID(id)
+	if err != nil {
+		return nil, err
 	}
+	return toTimeResponse(interview, time.Now()), nil
+}
 
-	s.evalRepo.CreateEvaluation(evaluation)
-
-	return &dto.EndInterviewResponse{
-		EvaluationID: evaluation.ID,
-		OverallScore: evaluation.OverallScore,
-		Feedback:     evaluation.DetailedFeedback,
-	}, nil
+// invalidateSession drops the cached session after a status change so the
+// next turn sees it.
+func (s *interviewService) invalidateSession(id uuid.UUID) {
+	if err := s.sessions.Delete(context.Background(), id); err != nil {
+		log.Warn().Err(err).Str("interview_id", id.String()).Msg("Failed to invalidate session cache")
+	}
 }
 
+func toTimeResponse(interview *model.Interview, now time.Time) *dto.InterviewTimeResponse {
+	res := &dto.InterviewTimeResponse{
+		InterviewID:     interview.ID,
+		Status:          string(interview.Status),
+		DurationMinutes: interview.DurationMinutes,
+		ActiveSeconds:   int64(activeTime(interview.ActiveSeconds, interview.ResumedAt, now) / time.Second),
+	}
+	if remaining, timed := remainingTime(interview.DurationMinutes, interview.ActiveSeconds, interview.ResumedAt, now); timed {
+		seconds := int64(remaining / time.Second)
+		res.RemainingSeconds = &seconds
+	}
+	return res
+}
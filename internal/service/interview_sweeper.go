@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"minos/config"
+	"minos/internal/cache"
+	"minos/internal/model"
+	"minos/internal/repository"
+	"sync"
+	"time"
+
+	"github.com/rs/zerolog/log"
+)
+
+// sweepBatchSize caps how many interviews of each kind one sweep closes.
+const sweepBatchSize = 100
+
+// InterviewSweeper periodically completes timed interviews whose budget ran
+// out and abandons interviews nobody has written to within the idle timeout,
+// or left paused past the paused timeout, so they stop counting against the
+// user's active limit.
+type InterviewSweeper interface {
+	Start() error
+	Stop(ctx context.Context) error
+	// Sweep runs one pass and returns how many interviews it closed.
+	Sweep() (int, error)
+}
+
+type interviewSweeper struct {
+	repo        repository.InterviewRepository
+	evaluations EvaluationService
+	sessions    cache.SessionCache
+	cfg         *config.Config
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+func NewInterviewSweeper(
+	repo repository.InterviewRepository,
+	evaluations EvaluationService,
+	sessions cache.SessionCache,
+	cfg *config.Config,
+) InterviewSweeper {
+	return &interviewSweeper{
+		repo:        repo,
+		evaluations: evaluations,
+		sessions:    sessions,
+		cfg:         cfg,
+	}
+}
+
+func (s *interviewSweeper) Start() error {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.cancel = cancel
+
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(time.Duration(s.cfg.Interview.SweepIntervalSeconds) * time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				if _, err := s.Sweep(); err != nil {
+					log.Error().Err(err).Msg("Failed to sweep interviews")
+				}
+			}
+		}
+	}()
+	log.Info().Int("idle_timeout_minutes", s.cfg.Interview.IdleTimeoutMinutes).Int("paused_timeout_minutes", s.cfg.Interview.PausedTimeoutMinutes).Msg("Interview sweeper started")
+	return nil
+}
+
+func (s *interviewSweeper) Stop(ctx context.Context) error {
+	if s.cancel == nil {
+		return nil
+	}
+	s.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		s.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (s *interviewSweeper) Sweep() (int, error) {
+	now := time.Now()
+	expired, err := s.repo.FindExpiredInterviews(now, sweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	cutoff := now.Add(-time.Duration(s.cfg.Interview.IdleTimeoutMinutes) * time.Minute)
+	idle, err := s.repo.FindIdleInterviews(cutoff, sweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+	pausedCutoff := now.Add(-time.Duration(s.cfg.Interview.PausedTimeoutMinutes) * time.Minute)
+	paused, err := s.repo.FindStalePausedInterviews(pausedCutoff, sweepBatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	closed := 0
+	for i := range expired {
+		// Time budget used up: the interview ends normally and is fully evaluated
+		if s.close(&expired[i], model.InterviewStatusCompleted, true) {
+			closed++
+		}
+	}
+	for i := range idle {
+		if s.close(&idle[i], model.InterviewStatusAbandoned, s.cfg.Interview.EvaluateAbandoned) {
+			closed++
+		}
+	}
+	for i := range paused {
+		if s.close(&paused[i], model.InterviewStatusAbandoned, s.cfg.Interview.EvaluateAbandoned) {
+			closed++
+		}
+	}
+	return closed, nil
+}
+
+// close ends an active or paused interview with the given status and reports
+// whether it did. The update is conditional on the status it was found in, so
+// an interview ended, paused, resumed or otherwise changed meanwhile is left
+// alone.
+func (s *interviewSweeper) close(interview *model.Interview, status model.InterviewStatus, evaluate bool) bool {
+	logger := log.With().Str("interview_id", interview.ID.String()).Str("status", string(status)).Logger()
+
+	from := interview.Status
+	now := time.Now()
+	stopClock(interview, now)
+	interview.Status = status
+	interview.EndedAt = &now
+	ok, err := s.repo.TransitionInterview(interview, from)
+	if err != nil {
+		logger.Error().Err(err).Msg("Failed to close interview")
+		return false
+	}
+	if !ok {
+		return false
+	}
+	logger.Info().Str("user_id", interview.UserID.String()).Msg("Interview closed by sweeper")
+
+	if err := s.sessions.Delete(context.Background(), interview.ID); err != nil {
+		logger.Warn().Err(err).Msg("Failed to invalidate session cache")
+	}
+	if evaluate {
+		if _, err := s.evaluations.RequestEvaluation(interview); err != nil {
+			logger.Error().Err(err).Msg("Failed to queue evaluation")
+		}
+	}
+	return true
+}
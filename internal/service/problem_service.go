@@ -0,0 +1,153 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"minos/internal/dto"
+	"minos/internal/model"
+	"minos/internal/repository"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// ErrProblemNotFound is returned when a problem is not in the problem bank.
+var ErrProblemNotFound = errors.New("problem not found")
+
+// ErrProblemSlugTaken is returned when another problem already uses a slug.
+var ErrProblemSlugTaken = errors.New("problem slug is already taken")
+
+// ProblemService manages the problem bank interviews are started from.
+type ProblemService interface {
+	CreateProblem(input *dto.ProblemCreate) (*model.Problem, error)
+	GetProblems(query *dto.ProblemQuery) ([]model.Problem, error)
+	GetProblemByID(id uuid.UUID) (*model.Problem, error)
+	UpdateProblem(id uuid.UUID, input *dto.ProblemUpdate) (*model.Problem, error)
+	DeleteProblem(id uuid.UUID) error
+}
+
+type problemService struct {
+	repo repository.ProblemRepository
+}
+
+func NewProblemService(repo repository.ProblemRepository) ProblemService {
+	return &problemService{repo: repo}
+}
+
+func (s *problemService) CreateProblem(input *dto.ProblemCreate) (*model.Problem, error) {
+	if err := s.checkSlug(input.Slug, uuid.Nil); err != nil {
+		return nil, err
+	}
+
+	problem := &model.Problem{
+		Slug:              input.Slug,
+		Title:             input.Title,
+		Statement:         input.Statement,
+		Difficulty:        input.Difficulty,
+		Tags:              input.Tags,
+		Constraints:       input.Constraints,
+		Examples:          input.Examples,
+		HiddenTestCases:   input.HiddenTestCases,
+		ReferenceSolution: input.ReferenceSolution,
+		ReferenceLanguage: input.ReferenceLanguage,
+		Editorial:         input.Editorial,
+	}
+	if err := s.repo.CreateProblem(problem); err != nil {
+		return nil, err
+	}
+	return problem, nil
+}
+
+func (s *problemService) GetProblems(query *dto.ProblemQuery) ([]model.Problem, error) {
+	return s.repo.FindProblems(repository.ProblemFilter{
+		Difficulty: model.ProblemDifficulty(query.Difficulty),
+		Tag:        query.Tag,
+		Search:     query.Search,
+	})
+}
+
+func (s *problemService) GetProblemByID(id uuid.UUID) (*model.Problem, error) {
+	problem, err := s.repo.FindProblemByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("%w: %s", ErrProblemNotFound, id)
+		}
+		return nil, err
+	}
+	return problem, nil
+}
+
+func (s *problemService) UpdateProblem(id uuid.UUID, input *dto.ProblemUpdate) (*model.Problem, error) {
+	problem, err := s.GetProblemByID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update only provided fields
+	if input.Slug != nil && *input.Slug != problem.Slug {
+		if err := s.checkSlug(*input.Slug, id); err != nil {
+			return nil, err
+		}
+		problem.Slug = *input.Slug
+	}
+	if input.Title != nil {
+		problem.Title = *input.Title
+	}
+	if input.Statement != nil {
+		problem.Statement = *input.Statement
+	}
+	if input.Difficulty != nil {
+		problem.Difficulty = *input.Difficulty
+	}
+	if input.Tags != nil {
+		problem.Tags = *input.Tags
+	}
+	if input.Constraints != nil {
+		problem.Constraints = *input.Constraints
+	}
+	if input.Examples != nil {
+		problem.Examples = *input.Examples
+	}
+	if input.HiddenTestCases != nil {
+		problem.HiddenTestCases = *input.HiddenTestCases
+	}
+	if input.ReferenceSolution != nil {
+		problem.ReferenceSolution = *input.ReferenceSolution
+	}
+	if input.ReferenceLanguage != nil {
+		problem.ReferenceLanguage = *input.ReferenceLanguage
+	}
+	if input.Editorial != nil {
+		problem.Editorial = *input.Editorial
+	}
+
+	if err := s.repo.UpdateProblem(problem); err != nil {
+		return nil, err
+	}
+	return problem, nil
+}
+
+func (s *problemService) DeleteProblem(id uuid.UUID) error {
+	if err := s.repo.DeleteProblem(id); err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("%w: %s", ErrProblemNotFound, id)
+		}
+		return err
+	}
+	return nil
+}
+
+// checkSlug fails when a problem other than self already uses slug.
+func (s *problemService) checkSlug(slug string, self uuid.UUID) error {
+	existing, err := s.repo.FindProblemBySlug(slug)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil
+		}
+		return err
+	}
+	if existing.ID != self {
+		return fmt.Errorf("%w: %s", ErrProblemSlugTaken, slug)
+	}
+	return nil
+}
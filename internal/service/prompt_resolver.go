@@ -0,0 +1,145 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"minos/internal/llm"
+	"minos/internal/model"
+	"minos/internal/repository"
+	"strings"
+
+	"github.com/rs/zerolog/log"
+	"gorm.io/gorm"
+)
+
+// ResolvedPrompt is the prompt content to use along with the template it came
+// from. TemplateID is nil when the built-in fallback was used.
+type ResolvedPrompt struct {
+	Name       string
+	Content    string
+	Variables  string
+	TemplateID *uint
+	Version    string
+}
+
+// Render fills the prompt with values. Values the template does not declare
+// are dropped, so a template may ignore inputs the service always provides.
+// Templates without a schema fall back to the built-in one for their name.
+func (p *ResolvedPrompt) Render(values map[string]interface{}) (string, error) {
+	raw := p.Variables
+	if strings.TrimSpace(raw) == "" {
+		raw = llm.DefaultPromptVariables[p.Name]
+	}
+	schema, err := llm.ParseVariables(raw)
+	if err != nil {
+		return "", fmt.Errorf("invalid variables for prompt '%s': %w", p.Name, err)
+	}
+
+	declared := make(map[string]interface{}, len(values))
+	for name, value := range values {
+		if _, ok := schema[name]; ok {
+			declared[name] = value
+		}
+	}
+
+	rendered, err := llm.Render(p.Content, schema, declared)
+	if err != nil {
+		return "", fmt.Errorf("failed to render prompt '%s': %w", p.Name, err)
+	}
+	return rendered, nil
+}
+
+type PromptResolver interface {
+	// Resolve returns the template on the production channel for name, else
+	// the latest active template, else the built-in prompt.
+	Resolve(name string) (*ResolvedPrompt, error)
+	// ResolvePinned returns the exact template previously recorded on an
+	// interview, falling back to Resolve when it is gone or was never set.
+	ResolvePinned(name string, templateID *uint) (*ResolvedPrompt, error)
+}
+
+type promptResolver struct {
+	repo repository.PromptTemplateRepository
+}
+
+func NewPromptResolver(repo repository.PromptTemplateRepository) PromptResolver {
+	return &promptResolver{repo: repo}
+}
+
+func (r *promptResolver) Resolve(name string) (*ResolvedPrompt, error) {
+	released, err := r.resolveChannel(name, model.PromptChannelProduction)
+	if err != nil || released != nil {
+		return released, err
+	}
+
+	template, err := r.repo.FindActivePromptTemplateByName(name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return builtinPrompt(name)
+		}
+		return nil, err
+	}
+	return fromTemplate(template), nil
+}
+
+func (r *promptResolver) ResolvePinned(name string, templateID *uint) (*ResolvedPrompt, error) {
+	if templateID == nil {
+		return builtinPrompt(name)
+	}
+
+	template, err := r.repo.FindPromptTemplateByID(*templateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn().Uint("template_id", *templateID).Str("name", name).Msg("Pinned prompt template not found, resolving active one")
+			return r.Resolve(name)
+		}
+		return nil, err
+	}
+	return fromTemplate(template), nil
+}
+
+// resolveChannel returns the template a channel points at, or nil when the
+// channel was never promoted or its template has since been deleted.
+func (r *promptResolver) resolveChannel(name, channel string) (*ResolvedPrompt, error) {
+	pc, err := r.repo.FindPromptChannel(name, channel)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	template, err := r.repo.FindPromptTemplateByID(pc.TemplateID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			log.Warn().Uint("template_id", pc.TemplateID).Str("name", name).Str("channel", channel).Msg("Channel points at a deleted prompt template")
+			return nil, nil
+		}
+		return nil, err
+	}
+	return fromTemplate(template), nil
+}
+
+func fromTemplate(template *model.PromptTemplate) *ResolvedPrompt {
+	id := template.ID
+	return &ResolvedPrompt{
+		Name:       template.Name,
+		Content:    template.Content,
+		Variables:  template.Variables,
+		TemplateID: &id,
+		Version:    template.Version,
+	}
+}
+
+func builtinPrompt(name string) (*ResolvedPrompt, error) {
+	content, ok := llm.DefaultPrompts[name]
+	if !ok {
+		return nil, fmt.Errorf("no prompt template named '%s'", name)
+	}
+	return &ResolvedPrompt{
+		Name:      name,
+		Content:   content,
+		Variables: llm.DefaultPromptVariables[name],
+		Version:   "builtin",
+	}, nil
+}
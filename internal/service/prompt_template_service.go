@@ -4,6 +4,7 @@ import (
 	"errors"
 	"fmt"
 	"minos/internal/dto"
+	"minos/internal/llm"
 	"minos/internal/model"
 	"minos/internal/repository"
 
@@ -18,6 +19,13 @@ type Service interface {
 	GetPromptTemplateByNameVersion(name, version string) (*model.PromptTemplate, error)
 	UpdatePromptTemplate(id uint, input *dto.PromptTemplateUpdate) (*model.PromptTemplate, error)
 	DeletePromptTemplate(id uint) error
+	RenderPromptTemplate(id uint, values map[string]interface{}) (string, error)
+
+	// Release channel methods
+	GetPromptChannels(name string) ([]model.PromptChannel, error)
+	GetPromptChannelHistory(name, channel string) ([]model.PromptChannelHistory, error)
+	PromotePromptTemplate(name, channel string, input *dto.PromptChannelPromote, actor string) (*model.PromptChannel, error)
+	RollbackPromptChannel(name, channel, actor string) (*model.PromptChannel, error)
 }
 
 type service struct {
@@ -39,6 +47,10 @@ func (s *service) CreatePromptTemplate(input *dto.PromptTemplateCreate) (*model.
 		return nil, fmt.Errorf("prompt template with name '%s' and version '%s' already exists", input.Name, input.Version)
 	}
 
+	if err := validatePromptTemplate(input.Content, input.Variables); err != nil {
+		return nil, err
+	}
+
 	template := &model.PromptTemplate{
 		Name:        input.Name,
 		Version:     input.Version,
@@ -113,6 +125,10 @@ func (s *service) UpdatePromptTemplate(id uint, input *dto.PromptTemplateUpdate)
 		template.IsActive = *input.IsActive
 	}
 
+	if err := validatePromptTemplate(template.Content, template.Variables); err != nil {
+		return nil, err
+	}
+
 	err = s.repo.UpdatePromptTemplate(template)
 	if err != nil {
 		return nil, err
@@ -136,3 +152,69 @@ func (s *service) DeletePromptTemplate(id uint) error {
 
 	return s.repo.DeletePromptTemplate(id)
 }
+
+func (s *service) RenderPromptTemplate(id uint, values map[string]interface{}) (string, error) {
+	template, err := s.GetPromptTemplateByID(id)
+	if err != nil {
+		return "", err
+	}
+
+	schema, err := llm.ParseVariables(template.Variables)
+	if err != nil {
+		return "", err
+	}
+	return llm.Render(template.Content, schema, values)
+}
+
+// validatePromptTemplate checks that the variables schema is well formed and
+// that content only references variables it declares.
+func validatePromptTemplate(content, variables string) error {
+	schema, err := llm.ParseVariables(variables)
+	if err != nil {
+		return err
+	}
+	return llm.ValidateTemplate(content, schema)
+}
+
+// Release channel methods
+func (s *service) GetPromptChannels(name string) ([]model.PromptChannel, error) {
+	return s.repo.FindPromptChannels(name)
+}
+
+func (s *service) GetPromptChannelHistory(name, channel string) ([]model.PromptChannelHistory, error) {
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+	return s.repo.FindPromptChannelHistory(name, channel)
+}
+
+func (s *service) PromotePromptTemplate(name, channel string, input *dto.PromptChannelPromote, actor string) (*model.PromptChannel, error) {
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+
+	template, err := s.GetPromptTemplateByNameVersion(name, input.Version)
+	if err != nil {
+		return nil, err
+	}
+	if !template.IsActive {
+		return nil, fmt.Errorf("prompt template '%s' version '%s' is inactive and cannot be promoted", name, input.Version)
+	}
+
+	return s.repo.PromotePromptChannel(template, channel, actor)
+}
+
+func (s *service) RollbackPromptChannel(name, channel, actor string) (*model.PromptChannel, error) {
+	if err := validateChannel(channel); err != nil {
+		return nil, err
+	}
+	return s.repo.RollbackPromptChannel(name, channel, actor)
+}
+
+func validateChannel(channel string) error {
+	switch channel {
+	case model.PromptChannelProduction, model.PromptChannelStaging, model.PromptChannelCanary:
+		return nil
+	}
+	return fmt.Errorf("unknown release channel '%s'", channel)
+}
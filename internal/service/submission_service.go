@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"minos/config"
+	"minos/internal/dto"
+	"minos/internal/llm"
+	"minos/internal/model"
+	"minos/internal/ratelimit"
+	"minos/internal/repository"
+	"minos/internal/runner"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/rs/zerolog/log"
+	"gorm.io/datatypes"
+)
+
+type SubmissionService interface {
+	SubmitCode(interviewID uuid.UUID, req *dto.SubmitCodeRequest) (*model.Submission, error)
+	GetSubmissions(interviewID uuid.UUID) ([]model.Submission, error)
+}
+
+type submissionService struct {
+	repo          repository.SubmissionRepository
+	interviewRepo repository.InterviewRepository
+	llmProvider   llm.Provider
+	codeRunner    runner.Runner
+	prompts       PromptResolver
+	budget        ratelimit.TokenBudget
+	prices        llm.PriceTable
+	cfg           *config.Config
+}
+
+func NewSubmissionService(
+	repo repository.SubmissionRepository,
+	interviewRepo repository.InterviewRepository,
+	llmProvider llm.Provider,
+	codeRunner runner.Runner,
+	prompts PromptResolver,
+	budget ratelimit.TokenBudget,
+	prices llm.PriceTable,
+	cfg *config.Config,
+) SubmissionService {
+	return &submissionService{
+		repo:          repo,
+		interviewRepo: interviewRepo,
+		llmProvider:   llmProvider,
+		codeRunner:    codeRunner,
+		prompts:       prompts,
+		budget:        budget,
+		prices:        prices,
+		cfg:           cfg,
+	}
+}
+
+type reviewResult struct {
+	IsCorrect        *bool           `json:"is_correct"`
+	Feedback         string          `json:"feedback"`
+	Complexity       string          `json:"complexity"`
+	Suggestions      []string        `json:"suggestions"`
+	SimulatedResults json.RawMessage `json:"simulated_results"`
+}
+
+// testResults is what gets stored in Submission.TestResults: real execution
+// results from the runner next to the results the reviewer simulated.
+type testResults struct {
+	Executed  []runner.TestResult `json:"executed,omitempty"`
+	Simulated json.RawMessage     `json:"simulated,omitempty"`
+}
+
+func (s *submissionService) SubmitCode(interviewID uuid.UUID, req *dto.SubmitCodeRequest) (*model.Submission, error) {
+	// 1. Validate Interview
+	interview, err := s.interviewRepo.FindInterviewByID(interviewID)
+	if err != nil {
+		return nil, err
+	}
+	if interview.Status != model.InterviewStatusActive {
+		return nil, fmt.Errorf("%w: interview is not active", ErrInvalidInterviewState)
+	}
+	if remaining, timed := remainingTime(interview.DurationMinutes, interview.ActiveSeconds, interview.ResumedAt, time.Now()); timed && remaining <= 0 {
+		return nil, fmt.Errorf("%w: interview time is up", ErrInvalidInterviewState)
+	}
+	if err := checkBudget(s.budget, interview.UserID); err != nil {
+		return nil, err
+	}
+
+	submission := &model.Submission{
+		InterviewID: interviewID,
+		Code:        req.Code,
+		Language:    req.Language,
+	}
+
+	// 2. Review the code. A failed review still keeps the submission so the
+	// evaluator sees every attempt.
+	var results testResults
+	simulated, err := s.review(interview, submission)
+	if err != nil {
+		log.Error().Err(err).Str("interview_id", interviewID.String()).Msg("Failed to review submission")
+	}
+	results.Simulated = simulated
+
+	// 3. Execute against the problem's hidden test cases, else the ones in
+	// its snapshot. Real results take precedence over the reviewer's verdict.
+	tests := runner.TestCasesFromSnapshot(interview.ProblemSnapshot)
+	if len(interview.HiddenTestCases) > 0 {
+		tests = make([]runner.TestCase, 0, len(interview.HiddenTestCases))
+		for _, tc := range interview.HiddenTestCases {
+			tests = append(tests, runner.TestCase{Input: tc.Input, Expected: tc.Expected, Hidden: true})
+		}
+	}
+	if len(tests) > 0 && s.codeRunner.Supports(req.Language) {
+		executed, err := s.codeRunner.Run(context.Background(), req.Language, req.Code, tests)
+		if err != nil {
+			log.Error().Err(err).Str("interview_id", interviewID.String()).Msg("Failed to execute submission")
+		} else {
+			results.Executed = executed
+			// Only a verdict on every test overrides the reviewer's
+			if len(executed) == len(tests) {
+				allPassed := true
+				for _, r := range executed {
+					allPassed = allPassed && r.Passed
+				}
+				submission.IsCorrect = &allPassed
+			}
+		}
+	}
+
+	if results.Executed != nil || results.Simulated != nil {
+		resultsJSON, _ := json.Marshal(results)
+		submission.TestResults = datatypes.JSON(resultsJSON)
+	}
+
+	// 4. Save Submission
+	if err := s.repo.CreateSubmission(submission); err != nil {
+		return nil, err
+	}
+
+	return submission, nil
+}
+
+// review fills in the reviewer's feedback and returns its simulated results.
+func (s *submissionService) review(interview *model.Interview, submission *model.Submission) (json.RawMessage, error) {
+	reviewerPrompt, err := s.prompts.Resolve(llm.PromptNameReviewer)
+	if err != nil {
+		return nil, err
+	}
+	submission.PromptTemplateID = reviewerPrompt.TemplateID
+	submission.PromptVersion = reviewerPrompt.Version
+
+	prompt, err := reviewerPrompt.Render(map[string]interface{}{
+		"problem":  string(interview.ProblemSnapshot),
+		"code":     submission.Code,
+		"language": submission.Language,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var res reviewResult
+	resp, err := llm.GenerateValidated(context.Background(), s.llmProvider, prompt, llm.ReviewSchema, &res, s.cfg.LLM.StructuredMaxAttempts)
+	chargeBudget(s.budget, interview.UserID, resp)
+	submission.TokenUsage = tokenUsage(s.prices, resp)
+	if resp != nil {
+		submission.Model = resp.Model
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review code: %w", err)
+	}
+
+	suggestionsJSON, _ := json.Marshal(res.Suggestions)
+
+	submission.IsCorrect = res.IsCorrect
+	submission.AIFeedback = res.Feedback
+	submission.Complexity = res.Complexity
+	submission.Suggestions = datatypes.JSON(suggestionsJSON)
+	if len(res.SimulatedResults) == 0 {
+		return nil, nil
+	}
+	return res.SimulatedResults, nil
+}
+
+func (s *submissionService) GetSubmissions(interviewID uuid.UUID) ([]model.Submission, error) {
+	return s.repo.FindSubmissionsByInterviewID(interviewID)
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"fmt"
+	"minos/internal/dto"
+	"minos/internal/llm"
+	"minos/internal/model"
+	"minos/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Default and maximum length in days of a usage report
+const (
+	usageReportDays    = 30
+	maxUsageReportDays = 366
+)
+
+type UsageService interface {
+	// GetUsageReport sums LLM usage by UTC day and model.
+	GetUsageReport(query *dto.UsageQuery) (*dto.UsageReport, error)
+}
+
+type usageService struct {
+	repo repository.UsageRepository
+}
+
+func NewUsageService(repo repository.UsageRepository) UsageService {
+	return &usageService{repo: repo}
+}
+
+func (s *usageService) GetUsageReport(query *dto.UsageQuery) (*dto.UsageReport, error) {
+	now := time.Now().UTC()
+	to := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if query.To != "" {
+		to, _ = time.Parse(time.DateOnly, query.To)
+	}
+	from := to.AddDate(0, 0, -(usageReportDays - 1))
+	if query.From != "" {
+		from, _ = time.Parse(time.DateOnly, query.From)
+	}
+	if from.After(to) {
+		return nil, fmt.Errorf("%w: from is after to", ErrInvalidQuery)
+	}
+	if to.Sub(from) >= maxUsageReportDays*24*time.Hour {
+		return nil, fmt.Errorf("%w: a report covers at most %d days", ErrInvalidQuery, maxUsageReportDays)
+	}
+
+	filter := repository.UsageFilter{From: from, To: to.AddDate(0, 0, 1)}
+	if query.UserID != "" {
+		userID := uuid.MustParse(query.UserID) // Validated by the binding
+		filter.UserID = &userID
+	}
+	days, err := s.repo.FindDailyUsage(filter)
+	if err != nil {
+		return nil, err
+	}
+	if days == nil {
+		days = []model.DailyUsage{}
+	}
+
+	report := &dto.UsageReport{
+		From: from.Format(time.DateOnly),
+		To:   to.Format(time.DateOnly),
+		Days: days,
+	}
+	for _, day := range days {
+		report.Total.Calls += day.Calls
+		report.Total.PromptTokens += day.PromptTokens
+		report.Total.CompletionTokens += day.CompletionTokens
+		report.Total.TotalTokens += day.TotalTokens
+		report.Total.CostUSD += day.CostUSD
+	}
+	return report, nil
+}
+
+// tokenUsage prices what a model call reported. A nil response cost nothing.
+func tokenUsage(prices llm.PriceTable, resp *llm.Response) model.TokenUsage {
+	if resp == nil {
+		return model.TokenUsage{}
+	}
+	return model.TokenUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+		CostUSD:          prices.Cost(resp.Model, resp.Usage),
+	}
+}